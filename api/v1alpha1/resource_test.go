@@ -0,0 +1,68 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/talos-systems/os-runtime/api/v1alpha1"
+)
+
+func TestMetadataWireFormat(t *testing.T) {
+	t.Parallel()
+
+	md := &v1alpha1.Metadata{
+		Namespace:  "ns",
+		Id:         "a",
+		Finalizers: []string{"x", ""},
+	}
+
+	b, err := proto.Marshal(md)
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte{0x0a, 2, 'n', 's', 0x1a, 1, 'a', 0x32, 1, 'x', 0x32, 0}, b)
+
+	var decoded v1alpha1.Metadata
+
+	require.NoError(t, proto.Unmarshal(b, &decoded))
+	assert.True(t, proto.Equal(md, &decoded))
+}
+
+func TestEventRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	event := &v1alpha1.Event{
+		EventType: v1alpha1.EventType_DESTROYED,
+		Resource: &v1alpha1.Resource{
+			Metadata: &v1alpha1.Metadata{
+				Namespace: "default",
+				Type:      "Tests.cosi.dev",
+				Id:        "a",
+				Version:   "3",
+				Phase:     "running",
+			},
+			Spec: &v1alpha1.Spec{
+				Yaml: []byte("a: b\n"),
+			},
+		},
+	}
+
+	b, err := proto.Marshal(event)
+	require.NoError(t, err)
+
+	// unknown fields should be skipped
+	b = append(b, 0x78, 0x01, 0x82, 0x01, 0x00)
+
+	var decoded v1alpha1.Event
+
+	require.NoError(t, proto.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, &decoded))
+	assert.True(t, proto.Equal(event, &decoded))
+
+	assert.Error(t, proto.Unmarshal([]byte{0x12, 0x10}, &decoded))
+}
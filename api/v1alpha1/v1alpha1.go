@@ -0,0 +1,8 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package v1alpha1 contains Go bindings generated from the resource.proto protobuf schema.
+package v1alpha1
+
+//go:generate protoc -I. --go_out=paths=source_relative:. resource.proto
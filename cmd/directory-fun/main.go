@@ -54,7 +54,14 @@ func DirectoryTask(world state.State, path string) {
 
 	log.Printf("%q: created %q", path, path)
 
-	if err = world.AddFinalizer(ctx, parent.Metadata(), self.String()); err != nil {
+	ctx = state.WithOwner(ctx, path)
+
+	fin, err := resource.OwnedFinalizer(path, "child")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = world.AddFinalizer(ctx, parent.Metadata(), fin); err != nil {
 		log.Fatal(err)
 	}
 
@@ -96,7 +103,7 @@ func DirectoryTask(world state.State, path string) {
 		log.Fatal(err)
 	}
 
-	if err = world.RemoveFinalizer(ctx, parent.Metadata(), self.String()); err != nil {
+	if err = world.RemoveFinalizer(ctx, parent.Metadata(), fin); err != nil {
 		log.Fatal(err)
 	}
 
@@ -6,9 +6,9 @@
 package main
 
 import (
-	"fmt"
-
 	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/resource/typed"
 )
 
 const PathResourceType = resource.Type("os/path")
@@ -17,33 +17,26 @@ const PathResourceType = resource.Type("os/path")
 //
 // Resource ID is the path, and dependents are all the immediate
 // children on the path.
-type PathResource struct {
-	md resource.Metadata
-}
+type PathResource = typed.Resource[PathSpec, PathExtension]
 
-func NewPathResource(ns resource.Namespace, path string) *PathResource {
-	r := &PathResource{
-		md: resource.NewMetadata(ns, PathResourceType, path, resource.VersionUndefined),
-	}
-	r.md.BumpVersion()
+type PathSpec struct{}
 
-	return r
+func (spec PathSpec) DeepCopy() PathSpec {
+	return spec
 }
 
-func (path *PathResource) Metadata() *resource.Metadata {
-	return &path.md
-}
+func NewPathResource(ns resource.Namespace, path string) *PathResource {
+	md := resource.NewMetadata(ns, PathResourceType, path, resource.VersionUndefined)
+	md.BumpVersion()
 
-func (path *PathResource) Spec() interface{} {
-	return nil
+	return typed.NewResource[PathSpec, PathExtension](md, PathSpec{})
 }
 
-func (path *PathResource) String() string {
-	return fmt.Sprintf("PathResource(%q)", path.md.ID())
-}
+type PathExtension struct{}
 
-func (path *PathResource) DeepCopy() resource.Resource {
-	return &PathResource{
-		md: path.md,
+func (PathExtension) ResourceDefinition() meta.ResourceDefinitionSpec {
+	return meta.ResourceDefinitionSpec{
+		Type:             PathResourceType,
+		DefaultNamespace: defaultNs,
 	}
 }
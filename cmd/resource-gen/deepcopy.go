@@ -0,0 +1,415 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+)
+
+// deepCopier generates deep copy code based on the type declarations in the package.
+//
+// Types declared in other packages are imported from the source: plain values are copied
+// by assignment, other types are copied with their DeepCopy method.
+type deepCopier struct {
+	pkg *packageInfo
+
+	importer types.ImporterFrom
+
+	memo     map[string]bool
+	visiting map[string]bool
+
+	// local struct types which need deepCopyXXX helpers
+	helpers map[string]struct{}
+
+	// packages referenced by the generated code (path -> import name, empty for the default one)
+	imports map[string]string
+
+	// first error encountered while generating the code
+	err error
+}
+
+func newDeepCopier(pkg *packageInfo) *deepCopier {
+	return &deepCopier{
+		pkg:      pkg,
+		importer: importer.ForCompiler(pkg.fset, "source", nil).(types.ImporterFrom), //nolint: errcheck
+		memo:     map[string]bool{},
+		visiting: map[string]bool{},
+		helpers:  map[string]struct{}{},
+		imports:  map[string]string{},
+	}
+}
+
+func (dc *deepCopier) fail(node ast.Node, err error) {
+	if dc.err == nil {
+		dc.err = fmt.Errorf("%s: %w", dc.pkg.fset.Position(node.Pos()), err)
+	}
+}
+
+// importOf finds the import of the package referenced by the selector expression.
+func (dc *deepCopier) importOf(sel *ast.SelectorExpr) (*ast.ImportSpec, *types.Package, error) {
+	pkgIdent, ok := sel.X.(*ast.Ident)
+	if !ok || dc.pkg.selectors[sel] == nil {
+		return nil, nil, fmt.Errorf("unsupported type %s", typeString(sel))
+	}
+
+	for _, spec := range dc.pkg.selectors[sel].Imports {
+		if spec.Name != nil && spec.Name.Name != pkgIdent.Name {
+			continue
+		}
+
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		imported, err := dc.importer.ImportFrom(path, dc.pkg.dir, 0)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error importing %q: %w", path, err)
+		}
+
+		if spec.Name == nil && imported.Name() != pkgIdent.Name {
+			continue
+		}
+
+		return spec, imported, nil
+	}
+
+	return nil, nil, fmt.Errorf("package %q is not imported", pkgIdent.Name)
+}
+
+// external resolves the type declared in another package.
+func (dc *deepCopier) external(sel *ast.SelectorExpr) (types.Type, error) {
+	_, imported, err := dc.importOf(sel)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, ok := imported.Scope().Lookup(sel.Sel.Name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not declared in %q", typeString(sel), imported.Path())
+	}
+
+	return obj.Type(), nil
+}
+
+// typeString formats the type expression for the generated code, recording the imports it requires.
+func (dc *deepCopier) typeString(expr ast.Expr) string {
+	ast.Inspect(expr, func(node ast.Node) bool {
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		spec, imported, err := dc.importOf(sel)
+		if err != nil {
+			dc.fail(sel, err)
+
+			return false
+		}
+
+		dc.imports[imported.Path()] = ""
+
+		if spec.Name != nil {
+			dc.imports[imported.Path()] = spec.Name.Name
+		}
+
+		return false
+	})
+
+	return typeString(expr)
+}
+
+// plainValue returns true if the value of the type can be copied by assignment.
+func plainValue(typ types.Type) bool {
+	switch t := typ.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map:
+		return false
+	case *types.Array:
+		return plainValue(t.Elem())
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if !plainValue(t.Field(i).Type()) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		// basic types, interfaces, funcs, channels
+		return true
+	}
+}
+
+func typeString(expr ast.Expr) string {
+	var buf bytes.Buffer
+
+	printer.Fprint(&buf, token.NewFileSet(), expr) //nolint: errcheck
+
+	return buf.String()
+}
+
+func (dc *deepCopier) needsDeepCopy(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.StarExpr, *ast.MapType:
+		return true
+	case *ast.ParenExpr:
+		return dc.needsDeepCopy(t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return true
+		}
+
+		return dc.needsDeepCopy(t.Elt)
+	case *ast.StructType:
+		for _, field := range t.Fields.List {
+			if dc.needsDeepCopy(field.Type) {
+				return true
+			}
+		}
+
+		return false
+	case *ast.Ident:
+		underlying, ok := dc.pkg.types[t.Name]
+		if !ok {
+			// builtin type
+			return false
+		}
+
+		if needs, ok := dc.memo[t.Name]; ok {
+			return needs
+		}
+
+		if dc.visiting[t.Name] {
+			return true
+		}
+
+		dc.visiting[t.Name] = true
+		needs := dc.needsDeepCopy(underlying)
+		delete(dc.visiting, t.Name)
+
+		dc.memo[t.Name] = needs
+
+		return needs
+	case *ast.SelectorExpr:
+		typ, err := dc.external(t)
+		if err != nil {
+			dc.fail(t, err)
+
+			return false
+		}
+
+		return !plainValue(typ)
+	default:
+		// interfaces, funcs, channels
+		return false
+	}
+}
+
+// emit writes statements which turn `out` (shallow copy of `in`) into a deep copy of `in`.
+func (dc *deepCopier) emit(buf *bytes.Buffer, out, in string, expr ast.Expr, depth int) {
+	if !dc.needsDeepCopy(expr) {
+		return
+	}
+
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		dc.emit(buf, out, in, t.X, depth)
+	case *ast.Ident:
+		underlying := dc.pkg.types[t.Name]
+
+		if _, isStruct := underlying.(*ast.StructType); isStruct {
+			dc.helpers[t.Name] = struct{}{}
+
+			fmt.Fprintf(buf, "%s = deepCopy%s(%s)\n", out, t.Name, in)
+
+			return
+		}
+
+		dc.emitComposite(buf, out, in, t.Name, underlying, depth)
+	case *ast.SelectorExpr:
+		dc.emitExternal(buf, out, in, t)
+	default:
+		dc.emitComposite(buf, out, in, "", expr, depth)
+	}
+}
+
+// emitComposite is emit for the composite types, typ is the name of the type if it is declared in the package.
+func (dc *deepCopier) emitComposite(buf *bytes.Buffer, out, in, typ string, expr ast.Expr, depth int) {
+	// type is formatted only when it is used, so that only the imports used by the generated code are recorded
+	typeName := func() string {
+		if typ != "" {
+			return typ
+		}
+
+		return dc.typeString(expr)
+	}
+
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		fmt.Fprintf(buf, "if %s != nil {\n", in)
+		fmt.Fprintf(buf, "%s = new(%s)\n", out, dc.typeString(t.X))
+		fmt.Fprintf(buf, "*%s = *%s\n", out, in)
+		dc.emit(buf, "(*"+out+")", "(*"+in+")", t.X, depth+1)
+		fmt.Fprintf(buf, "}\n")
+	case *ast.ArrayType:
+		idx := fmt.Sprintf("i%d", depth)
+
+		if t.Len == nil {
+			fmt.Fprintf(buf, "if %s != nil {\n", in)
+			fmt.Fprintf(buf, "%s = make(%s, len(%s))\n", out, typeName(), in)
+			fmt.Fprintf(buf, "copy(%s, %s)\n", out, in)
+		}
+
+		if dc.needsDeepCopy(t.Elt) {
+			fmt.Fprintf(buf, "for %s := range %s {\n", idx, in)
+			dc.emit(buf, out+"["+idx+"]", in+"["+idx+"]", t.Elt, depth+1)
+			fmt.Fprintf(buf, "}\n")
+		}
+
+		if t.Len == nil {
+			fmt.Fprintf(buf, "}\n")
+		}
+	case *ast.MapType:
+		key, val, cp := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth), fmt.Sprintf("c%d", depth)
+
+		fmt.Fprintf(buf, "if %s != nil {\n", in)
+		fmt.Fprintf(buf, "%s = make(%s, len(%s))\n", out, typeName(), in)
+		fmt.Fprintf(buf, "for %s, %s := range %s {\n", key, val, in)
+
+		if dc.needsDeepCopy(t.Value) {
+			fmt.Fprintf(buf, "%s := %s\n", cp, val)
+			dc.emit(buf, cp, val, t.Value, depth+1)
+			fmt.Fprintf(buf, "%s[%s] = %s\n", out, key, cp)
+		} else {
+			fmt.Fprintf(buf, "%s[%s] = %s\n", out, key, val)
+		}
+
+		fmt.Fprintf(buf, "}\n")
+		fmt.Fprintf(buf, "}\n")
+	case *ast.StructType:
+		dc.emitFields(buf, out, in, t, depth)
+	}
+}
+
+// emitExternal copies the value of the type declared in another package with its DeepCopy method.
+func (dc *deepCopier) emitExternal(buf *bytes.Buffer, out, in string, sel *ast.SelectorExpr) {
+	typ, err := dc.external(sel)
+	if err != nil {
+		dc.fail(sel, err)
+
+		return
+	}
+
+	if method, ok := lookupDeepCopy(typ); ok {
+		sig := method.Type().(*types.Signature) //nolint: errcheck
+
+		switch result := sig.Results().At(0).Type(); {
+		case types.Identical(result, typ):
+			fmt.Fprintf(buf, "%s = %s.DeepCopy()\n", out, in)
+
+			return
+		case types.Identical(result, types.NewPointer(typ)):
+			fmt.Fprintf(buf, "%s = *%s.DeepCopy()\n", out, in)
+
+			return
+		}
+	}
+
+	dc.fail(sel, fmt.Errorf("type %s can't be copied by assignment, and it has no DeepCopy method returning %s or *%s", typeString(sel), typeString(sel), typeString(sel)))
+}
+
+func lookupDeepCopy(typ types.Type) (*types.Func, bool) {
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, "DeepCopy")
+
+	method, ok := obj.(*types.Func)
+	if !ok {
+		return nil, false
+	}
+
+	sig := method.Type().(*types.Signature) //nolint: errcheck
+
+	return method, sig.Params().Len() == 0 && sig.Results().Len() == 1
+}
+
+func (dc *deepCopier) emitFields(buf *bytes.Buffer, out, in string, t *ast.StructType, depth int) {
+	for _, field := range t.Fields.List {
+		names := make([]string, 0, len(field.Names))
+
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+
+		if len(names) == 0 {
+			// embedded field
+			names = append(names, embeddedName(field.Type))
+		}
+
+		for _, name := range names {
+			if name == "_" {
+				continue
+			}
+
+			dc.emit(buf, out+"."+name, in+"."+name, field.Type, depth)
+		}
+	}
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	default:
+		return typeString(expr)
+	}
+}
+
+// function generates deep copy function (or method) body for a local struct type.
+func (dc *deepCopier) function(buf *bytes.Buffer, signature, typeName string) {
+	fmt.Fprintf(buf, "%s {\n", signature)
+	fmt.Fprintf(buf, "out := in\n\n")
+
+	dc.emitFields(buf, "out", "in", dc.pkg.types[typeName].(*ast.StructType), 0)
+
+	fmt.Fprintf(buf, "\nreturn out\n}\n\n")
+}
+
+// generateHelpers generates deepCopyXXX helpers for all struct types referenced so far.
+func (dc *deepCopier) generateHelpers(buf *bytes.Buffer) {
+	generated := map[string]struct{}{}
+
+	for {
+		pending := make([]string, 0, len(dc.helpers))
+
+		for name := range dc.helpers {
+			if _, ok := generated[name]; !ok {
+				pending = append(pending, name)
+			}
+		}
+
+		if len(pending) == 0 {
+			return
+		}
+
+		sort.Strings(pending)
+
+		for _, name := range pending {
+			generated[name] = struct{}{}
+
+			dc.function(buf, fmt.Sprintf("func deepCopy%s(in %s) %s", name, name, name), name)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+var resourceTemplate = template.Must(template.New("resource").Parse(`
+// {{ .Name }}Type is the type of {{ .Name }}.
+const {{ .Name }}Type = resource.Type({{ printf "%q" .Type }})
+
+// {{ .Name }} is a resource with {{ .SpecName }} spec.
+type {{ .Name }} struct {
+	md   resource.Metadata
+	spec {{ .SpecName }}
+}
+
+// New{{ .Name }} initializes a {{ .Name }} resource.
+func New{{ .Name }}(ns resource.Namespace, id resource.ID, spec {{ .SpecName }}) *{{ .Name }} {
+	r := &{{ .Name }}{
+		md:   resource.NewMetadata(ns, {{ .Name }}Type, id, resource.VersionUndefined),
+		spec: spec,
+	}
+
+	r.md.BumpVersion()
+
+	return r
+}
+
+// New{{ .Name }}FromProto unmarshals {{ .Name }} from protobuf interfaces.
+func New{{ .Name }}FromProto(protoMd resource.MetadataProto, protoSpec resource.SpecProto) (*{{ .Name }}, error) {
+	md, err := resource.NewMetadataFromProto(protoMd)
+	if err != nil {
+		return nil, err
+	}
+
+	if md.Type() != {{ .Name }}Type {
+		return nil, fmt.Errorf("unexpected resource type %q, expected %q", md.Type(), {{ .Name }}Type)
+	}
+
+	r := &{{ .Name }}{
+		md: md,
+	}
+
+	if err = r.UnmarshalSpecYAML(protoSpec.GetYaml()); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Metadata implements resource.Resource.
+func (r *{{ .Name }}) Metadata() *resource.Metadata {
+	return &r.md
+}
+
+// Spec implements resource.Resource.
+func (r *{{ .Name }}) Spec() interface{} {
+	return r.spec
+}
+
+// TypedSpec returns a pointer to the spec which can be used to update it.
+func (r *{{ .Name }}) TypedSpec() *{{ .SpecName }} {
+	return &r.spec
+}
+
+func (r *{{ .Name }}) String() string {
+	return fmt.Sprintf("{{ .Name }}(%q)", r.md.ID())
+}
+
+// DeepCopy implements resource.Resource.
+func (r *{{ .Name }}) DeepCopy() resource.Resource {
+	return &{{ .Name }}{
+		md:   r.md.Copy(),
+		spec: r.spec.DeepCopy(),
+	}
+}
+
+// ResourceDefinition implements meta.ResourceDefinitionProvider interface.
+func (r *{{ .Name }}) ResourceDefinition() meta.ResourceDefinitionSpec {
+	return meta.ResourceDefinitionSpec{
+		Type:             {{ .Name }}Type,
+		DefaultNamespace: {{ printf "%q" .Namespace }},
+		{{- if .Aliases }}
+		Aliases: []resource.Type{
+			{{- range .Aliases }}
+			{{ printf "%q" . }},
+			{{- end }}
+		},
+		{{- end }}
+		{{- if .Columns }}
+		PrintColumns: []meta.PrintColumn{
+			{{- range .Columns }}
+			{
+				Name:     {{ printf "%q" .Name }},
+				JSONPath: {{ printf "%q" .JSONPath }},
+			},
+			{{- end }}
+		},
+		{{- end }}
+	}
+}
+
+// MarshalYAML implements yaml.Marshaler interface.
+func (r *{{ .Name }}) MarshalYAML() (interface{}, error) {
+	return resource.MarshalYAML(r)
+}
+
+// MarshalSpecYAML encodes the spec as YAML, e.g. for the protobuf representation.
+func (r *{{ .Name }}) MarshalSpecYAML() ([]byte, error) {
+	return yaml.Marshal(r.spec)
+}
+
+// UnmarshalSpecYAML implements resource.SpecUnmarshaler.
+func (r *{{ .Name }}) UnmarshalSpecYAML(data []byte) error {
+	var spec {{ .SpecName }}
+
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+
+	r.spec = spec
+
+	return nil
+}
+`))
+
+// packages imported by the generated code regardless of the spec types.
+var generatedImports = map[string]struct{}{
+	"fmt":              {},
+	"gopkg.in/yaml.v3": {},
+	"github.com/talos-systems/os-runtime/pkg/resource":      {},
+	"github.com/talos-systems/os-runtime/pkg/resource/meta": {},
+}
+
+func generate(pkg *packageInfo) ([]byte, error) {
+	var body bytes.Buffer
+
+	dc := newDeepCopier(pkg)
+
+	for _, res := range pkg.resources {
+		if err := resourceTemplate.Execute(&body, res); err != nil {
+			return nil, fmt.Errorf("error generating resource %q: %w", res.Name, err)
+		}
+
+		body.WriteString("\n")
+
+		if !res.HasDeepCopy {
+			fmt.Fprintf(&body, "// DeepCopy generates a deep copy of %s.\n", res.SpecName)
+			dc.function(&body, fmt.Sprintf("func (in %s) DeepCopy() %s", res.SpecName, res.SpecName), res.SpecName)
+		}
+	}
+
+	dc.generateHelpers(&body)
+
+	if dc.err != nil {
+		return nil, dc.err
+	}
+
+	// packages referenced by the deep copy code
+	paths := make([]string, 0, len(dc.imports))
+
+	for path, name := range dc.imports {
+		if _, ok := generatedImports[path]; ok && name == "" {
+			continue
+		}
+
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	var imports strings.Builder
+
+	if len(paths) > 0 {
+		imports.WriteString("\n")
+	}
+
+	for _, path := range paths {
+		if name := dc.imports[path]; name != "" {
+			fmt.Fprintf(&imports, "%s ", name)
+		}
+
+		fmt.Fprintf(&imports, "%q\n", path)
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, `// Code generated by resource-gen. DO NOT EDIT.
+
+package %s
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+%s)
+`, pkg.name, imports.String())
+
+	buf.Write(body.Bytes())
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated code: %w\n%s", err, buf.String())
+	}
+
+	return out, nil
+}
@@ -0,0 +1,64 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Command resource-gen generates resource.Resource implementations for annotated spec structs.
+//
+// Spec structs are annotated with directive comments:
+//
+//	//resource:type Namespaces.meta.cosi.dev
+//	//resource:name Namespace
+//	//resource:namespace meta
+//	//resource:alias ns
+//	//resource:column Description .description
+//	type NamespaceSpec struct {
+//		Description string `yaml:"description"`
+//	}
+//
+// Only `//resource:type` is required, resource name defaults to the spec name without the `Spec` suffix.
+// Fields of the types declared in other packages are copied with their DeepCopy method,
+// unless they can be copied by assignment.
+// Generator is supposed to be invoked via go generate:
+//
+//	//go:generate go run github.com/talos-systems/os-runtime/cmd/resource-gen
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("resource-gen: ")
+
+	dir := flag.String("dir", ".", "directory of the package to process")
+	output := flag.String("output", "resource_generated.go", "name of the generated file (relative to the package directory)")
+
+	flag.Parse()
+
+	if err := run(*dir, *output); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(dir, output string) error {
+	pkg, err := parsePackage(dir, output)
+	if err != nil {
+		return err
+	}
+
+	if len(pkg.resources) == 0 {
+		return fmt.Errorf("no annotated spec structs found in %q", dir)
+	}
+
+	out, err := generate(pkg)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, output), out, 0o644)
+}
@@ -0,0 +1,137 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	const dir = "testdata/widget"
+
+	pkg, err := parsePackage(dir, "resource_generated.go")
+	require.NoError(t, err)
+
+	out, err := generate(pkg)
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile(filepath.Join(dir, "resource_generated.go.golden"))
+	require.NoError(t, err)
+
+	assert.Equal(t, string(expected), string(out))
+
+	// generated code should compile along with the package
+	fset := token.NewFileSet()
+
+	var files []*ast.File
+
+	for _, name := range []string{"spec.go", "resource_generated.go"} {
+		src := out
+
+		if name == "spec.go" {
+			src, err = os.ReadFile(filepath.Join(dir, name))
+			require.NoError(t, err)
+		}
+
+		file, err := parser.ParseFile(fset, name, src, 0)
+		require.NoError(t, err)
+
+		files = append(files, file)
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+	}
+
+	_, err = conf.Check(pkg.name, fset, files, nil)
+	require.NoError(t, err)
+}
+
+func TestValidation(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name          string
+		source        string
+		expectedError string
+	}{
+		{
+			name: "singular",
+			source: `package test
+
+//resource:type Test.cosi.dev
+type TestSpec struct{}
+`,
+			expectedError: `invalid resource type "Test.cosi.dev": name should be plural`,
+		},
+		{
+			name: "unknown",
+			source: `package test
+
+//resource:type Tests.cosi.dev
+//resource:kind foo
+type TestSpec struct{}
+`,
+			expectedError: `unknown directive "kind"`,
+		},
+		{
+			name: "name",
+			source: `package test
+
+//resource:type Tests.cosi.dev
+type Test struct{}
+`,
+			expectedError: `resource name can't be derived from spec name "Test", use //resource:name directive`,
+		},
+		{
+			name: "notStruct",
+			source: `package test
+
+//resource:type Tests.cosi.dev
+type TestSpec []string
+`,
+			expectedError: `annotated type "TestSpec" is not a struct`,
+		},
+		{
+			name: "external",
+			source: `package test
+
+import "bytes"
+
+//resource:type Tests.cosi.dev
+type TestSpec struct {
+	Buffer bytes.Buffer
+}
+`,
+			expectedError: `type bytes.Buffer can't be copied by assignment, and it has no DeepCopy method returning bytes.Buffer or *bytes.Buffer`,
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "spec.go"), []byte(tt.source), 0o644))
+
+			err := run(dir, "resource_generated.go")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/talos-systems/os-runtime/pkg/resource/meta/spec"
+)
+
+const directivePrefix = "//resource:"
+
+type printColumn struct {
+	Name     string
+	JSONPath string
+}
+
+type resourceInfo struct {
+	Name      string
+	SpecName  string
+	Type      string
+	Namespace string
+	Aliases   []string
+	Columns   []printColumn
+
+	// HasDeepCopy is set if the spec already defines DeepCopy method.
+	HasDeepCopy bool
+}
+
+type packageInfo struct {
+	name string
+	dir  string
+	fset *token.FileSet
+
+	// types declared in the package (name -> type expression)
+	types map[string]ast.Expr
+
+	// references to the types declared in other packages (selector -> file it appears in)
+	selectors map[*ast.SelectorExpr]*ast.File
+
+	// methods declared in the package (receiver type name -> method names)
+	methods map[string]map[string]struct{}
+
+	resources []*resourceInfo
+}
+
+func parsePackage(dir, output string) (*packageInfo, error) {
+	fset := token.NewFileSet()
+
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != filepath.Base(output)
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected exactly one package in %q, found %d", dir, len(pkgs))
+	}
+
+	pkg := &packageInfo{
+		dir:       dir,
+		fset:      fset,
+		types:     map[string]ast.Expr{},
+		selectors: map[*ast.SelectorExpr]*ast.File{},
+		methods:   map[string]map[string]struct{}{},
+	}
+
+	for name, astPkg := range pkgs {
+		pkg.name = name
+
+		fileNames := make([]string, 0, len(astPkg.Files))
+
+		for fileName := range astPkg.Files {
+			fileNames = append(fileNames, fileName)
+		}
+
+		sort.Strings(fileNames)
+
+		for _, fileName := range fileNames {
+			if err = pkg.collect(fset, astPkg.Files[fileName]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, res := range pkg.resources {
+		_, res.HasDeepCopy = pkg.methods[res.SpecName]["DeepCopy"]
+	}
+
+	return pkg, nil
+}
+
+func (pkg *packageInfo) collect(fset *token.FileSet, file *ast.File) error {
+	ast.Inspect(file, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			pkg.selectors[sel] = file
+		}
+
+		return true
+	})
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) != 1 {
+				continue
+			}
+
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+
+			if ident, ok := recv.(*ast.Ident); ok {
+				if pkg.methods[ident.Name] == nil {
+					pkg.methods[ident.Name] = map[string]struct{}{}
+				}
+
+				pkg.methods[ident.Name][decl.Name.Name] = struct{}{}
+			}
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, s := range decl.Specs {
+				typeSpec := s.(*ast.TypeSpec) //nolint: errcheck
+
+				pkg.types[typeSpec.Name.Name] = typeSpec.Type
+
+				doc := typeSpec.Doc
+				if doc == nil && len(decl.Specs) == 1 {
+					doc = decl.Doc
+				}
+
+				res, err := parseDirectives(typeSpec.Name.Name, doc)
+				if err != nil {
+					return fmt.Errorf("%s: %w", fset.Position(typeSpec.Pos()), err)
+				}
+
+				if res == nil {
+					continue
+				}
+
+				if _, ok := typeSpec.Type.(*ast.StructType); !ok {
+					return fmt.Errorf("%s: annotated type %q is not a struct", fset.Position(typeSpec.Pos()), typeSpec.Name.Name)
+				}
+
+				pkg.resources = append(pkg.resources, res)
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseDirectives(specName string, doc *ast.CommentGroup) (*resourceInfo, error) {
+	if doc == nil {
+		return nil, nil
+	}
+
+	var (
+		res   resourceInfo
+		found bool
+	)
+
+	for _, comment := range doc.List {
+		if !strings.HasPrefix(comment.Text, directivePrefix) {
+			continue
+		}
+
+		found = true
+
+		fields := strings.Fields(strings.TrimPrefix(comment.Text, directivePrefix))
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty directive %q", comment.Text)
+		}
+
+		key, args := fields[0], fields[1:]
+
+		expectArgs := 1
+		if key == "column" {
+			expectArgs = 2
+		}
+
+		if len(args) != expectArgs {
+			return nil, fmt.Errorf("directive %q expects %d argument(s), got %d", key, expectArgs, len(args))
+		}
+
+		switch key {
+		case "type":
+			res.Type = args[0]
+		case "name":
+			res.Name = args[0]
+		case "namespace":
+			res.Namespace = args[0]
+		case "alias":
+			res.Aliases = append(res.Aliases, args[0])
+		case "column":
+			res.Columns = append(res.Columns, printColumn{
+				Name:     args[0],
+				JSONPath: args[1],
+			})
+		default:
+			return nil, fmt.Errorf("unknown directive %q", key)
+		}
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	res.SpecName = specName
+
+	if res.Name == "" {
+		res.Name = strings.TrimSuffix(specName, "Spec")
+
+		if res.Name == specName {
+			return nil, fmt.Errorf("resource name can't be derived from spec name %q, use %sname directive", specName, directivePrefix)
+		}
+	}
+
+	if !ast.IsExported(res.Name) {
+		return nil, fmt.Errorf("resource name %q should be exported", res.Name)
+	}
+
+	definition := spec.ResourceDefinitionSpec{
+		Type:             res.Type,
+		DefaultNamespace: res.Namespace,
+		Aliases:          append([]string(nil), res.Aliases...),
+	}
+
+	if err := definition.Fill(); err != nil {
+		return nil, fmt.Errorf("invalid resource type %q: %w", res.Type, err)
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,53 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package widget
+
+import (
+	"time"
+
+	"github.com/talos-systems/os-runtime/pkg/resource/meta/spec"
+)
+
+// Labels is a set of key-value pairs.
+type Labels map[string]string
+
+// Item is a nested struct.
+type Item struct {
+	Name  string
+	Tags  []string
+	Next  *Item
+	Extra map[string][]int
+}
+
+// WidgetSpec describes a widget.
+//
+//resource:type Widgets.test.dev
+//resource:namespace default
+//resource:alias wd
+//resource:column Size .size
+type WidgetSpec struct {
+	Size    int           `yaml:"size"`
+	Labels  Labels        `yaml:"labels"`
+	Items   []Item        `yaml:"items"`
+	Ptr     *int          `yaml:"ptr"`
+	Timeout time.Duration `yaml:"timeout"`
+	Chunks  [2][]byte     `yaml:"chunks"`
+
+	Definition  spec.ResourceDefinitionSpec             `yaml:"definition"`
+	Definitions map[string]*spec.ResourceDefinitionSpec `yaml:"definitions"`
+}
+
+// GadgetSpec describes a gadget.
+//
+//resource:type Gadgets.test.dev
+//resource:name Gizmo
+type GadgetSpec struct {
+	Value string `yaml:"value"`
+}
+
+// DeepCopy is implemented manually.
+func (spec GadgetSpec) DeepCopy() GadgetSpec {
+	return spec
+}
@@ -1,6 +1,6 @@
 module github.com/talos-systems/os-runtime
 
-go 1.14
+go 1.18
 
 require (
 	github.com/AlekSi/pointer v1.1.0
@@ -11,5 +11,15 @@ require (
 	github.com/talos-systems/go-retry v0.2.0
 	go.uber.org/goleak v1.1.10
 	golang.org/x/sync v0.0.0-20190423024810-112230192c58
+	google.golang.org/protobuf v1.28.1
 	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/hashicorp/go-immutable-radix v1.3.0 // indirect
+	github.com/hashicorp/golang-lru v0.5.4 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/lint v0.0.0-20190930215403-16217165b5de // indirect
+	golang.org/x/tools v0.0.0-20191108193012-7d206e10da11 // indirect
+)
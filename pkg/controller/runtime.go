@@ -59,6 +59,8 @@ type Reader interface {
 // Writer provides write access to the state.
 //
 // Only managed objects can be written to by the controller.
+// Finalizers should be owned by the controller (see resource.OwnedFinalizer), so the
+// controller can't remove finalizers set by other controllers.
 type Writer interface {
 	Update(context.Context, resource.Resource, func(resource.Resource) error) error
 	Teardown(context.Context, resource.Pointer) (bool, error)
@@ -145,22 +145,43 @@ func (adapter *adapter) checkReadAccess(resourceNamespace resource.Namespace, re
 	return fmt.Errorf("attempt to query resource %q/%q, not watched or managed by controller %q", resourceNamespace, resourceType, adapter.name)
 }
 
-func (adapter *adapter) checkFinalizerAccess(resourceNamespace resource.Namespace, resourceType resource.Type, resourceID resource.ID) error {
+func (adapter *adapter) checkFinalizerAccess(resourceNamespace resource.Namespace, resourceType resource.Type, resourceID resource.ID, fins []resource.Finalizer) error {
+	if !adapter.hasStrongDependency(resourceNamespace, resourceType, resourceID) {
+		return fmt.Errorf("attempt to change finalizers for resource %q/%q, not watched with Strong dependency by controller %q", resourceNamespace, resourceType, adapter.name)
+	}
+
+	// finalizers should be owned by the controller
+	for _, fin := range fins {
+		owner, _ := resource.FinalizerOwner(fin)
+
+		switch owner {
+		case adapter.name:
+		case "":
+			return fmt.Errorf("attempt to change finalizer %q for resource %q/%q not owned by controller %q, see resource.OwnedFinalizer", fin, resourceNamespace, resourceType, adapter.name)
+		default:
+			return fmt.Errorf("attempt to change finalizer %q for resource %q/%q owned by %q by controller %q", fin, resourceNamespace, resourceType, owner, adapter.name)
+		}
+	}
+
+	return nil
+}
+
+func (adapter *adapter) hasStrongDependency(resourceNamespace resource.Namespace, resourceType resource.Type, resourceID resource.ID) bool {
 	// go over cached dependencies here
 	for _, dep := range adapter.dependencies {
 		if dep.Namespace == resourceNamespace && dep.Type == resourceType && dep.Kind == controller.DependencyStrong {
 			// any ID is allowed
 			if dep.ID == nil {
-				return nil
+				return true
 			}
 
 			if *dep.ID == resourceID {
-				return nil
+				return true
 			}
 		}
 	}
 
-	return fmt.Errorf("attempt to change finalizers for resource %q/%q, not watched with Strong dependency by controller %q", resourceNamespace, resourceType, adapter.name)
+	return false
 }
 
 // Get implements controller.Runtime interface.
@@ -197,41 +218,27 @@ func (adapter *adapter) Update(ctx context.Context, emptyResource resource.Resou
 			emptyResource.Metadata().Namespace(), emptyResource.Metadata().Type(), adapter.name, emptyResource.Metadata().ID())
 	}
 
-	_, err := adapter.runtime.state.Get(ctx, emptyResource.Metadata())
-	if err != nil {
-		if state.IsNotFoundError(err) {
-			err = updateFunc(emptyResource)
-			if err != nil {
-				return err
-			}
-
-			return adapter.runtime.state.Create(ctx, emptyResource)
-		}
-
-		return fmt.Errorf("error querying current object state: %w", err)
-	}
-
-	_, err = adapter.runtime.state.UpdateWithConflicts(ctx, emptyResource.Metadata(), updateFunc)
+	_, err := adapter.runtime.state.Apply(state.WithOwner(ctx, adapter.name), emptyResource, state.WithApplyUpdater(updateFunc))
 
 	return err
 }
 
 // AddFinalizer implements controller.Runtime interface.
 func (adapter *adapter) AddFinalizer(ctx context.Context, resourcePointer resource.Pointer, fins ...resource.Finalizer) error {
-	if err := adapter.checkFinalizerAccess(resourcePointer.Namespace(), resourcePointer.Type(), resourcePointer.ID()); err != nil {
+	if err := adapter.checkFinalizerAccess(resourcePointer.Namespace(), resourcePointer.Type(), resourcePointer.ID(), fins); err != nil {
 		return err
 	}
 
-	return adapter.runtime.state.AddFinalizer(ctx, resourcePointer, fins...)
+	return adapter.runtime.state.AddFinalizer(state.WithOwner(ctx, adapter.name), resourcePointer, fins...)
 }
 
 // RemoveFinalizer impleemnts controller.Runtime interface.
 func (adapter *adapter) RemoveFinalizer(ctx context.Context, resourcePointer resource.Pointer, fins ...resource.Finalizer) error {
-	if err := adapter.checkFinalizerAccess(resourcePointer.Namespace(), resourcePointer.Type(), resourcePointer.ID()); err != nil {
+	if err := adapter.checkFinalizerAccess(resourcePointer.Namespace(), resourcePointer.Type(), resourcePointer.ID(), fins); err != nil {
 		return err
 	}
 
-	err := adapter.runtime.state.RemoveFinalizer(ctx, resourcePointer, fins...)
+	err := adapter.runtime.state.RemoveFinalizer(state.WithOwner(ctx, adapter.name), resourcePointer, fins...)
 	if state.IsNotFoundError(err) {
 		err = nil
 	}
@@ -65,6 +65,11 @@ func (runtime *Runtime) RegisterController(ctrl controller.Controller) error {
 
 	name := ctrl.Name()
 
+	// controller name is recorded as the owner of the finalizers
+	if err := resource.ValidateOwner(name); err != nil {
+		return fmt.Errorf("invalid controller name %q: %w", name, err)
+	}
+
 	if _, exists := runtime.controllers[name]; exists {
 		return fmt.Errorf("controller %q already registered", name)
 	}
@@ -64,9 +64,15 @@ func (ctrl *IntToStrController) Run(ctx context.Context, r controller.Runtime, l
 
 			strRes := NewStrResource(ctrl.TargetNamespace, intRes.Metadata().ID(), "")
 
+			var fin resource.Finalizer
+
+			if fin, err = resource.OwnedFinalizer(ctrl.Name(), strRes.String()); err != nil {
+				return err
+			}
+
 			switch intRes.Metadata().Phase() {
 			case resource.PhaseRunning:
-				if err = r.AddFinalizer(ctx, intRes.Metadata(), strRes.String()); err != nil {
+				if err = r.AddFinalizer(ctx, intRes.Metadata(), fin); err != nil {
 					return fmt.Errorf("error adding finalizer: %w", err)
 				}
 
@@ -82,7 +88,7 @@ func (ctrl *IntToStrController) Run(ctx context.Context, r controller.Runtime, l
 				ready, err := r.Teardown(ctx, strRes.Metadata())
 				if err != nil {
 					if state.IsNotFoundError(err) {
-						if err = r.RemoveFinalizer(ctx, intRes.Metadata(), strRes.String()); err != nil {
+						if err = r.RemoveFinalizer(ctx, intRes.Metadata(), fin); err != nil {
 							return fmt.Errorf("error removing finalizer (str controller): %w", err)
 						}
 
@@ -103,7 +109,7 @@ func (ctrl *IntToStrController) Run(ctx context.Context, r controller.Runtime, l
 					return fmt.Errorf("error destroying: %w", err)
 				}
 
-				if err = r.RemoveFinalizer(ctx, intRes.Metadata(), strRes.String()); err != nil {
+				if err = r.RemoveFinalizer(ctx, intRes.Metadata(), fin); err != nil {
 					if !state.IsNotFoundError(err) {
 						return fmt.Errorf("error removing finalizer (str controller): %w", err)
 					}
@@ -162,9 +168,15 @@ func (ctrl *StrToSentenceController) Run(ctx context.Context, r controller.Runti
 
 			sentenceRes := NewSentenceResource(ctrl.TargetNamespace, strRes.Metadata().ID(), "")
 
+			var fin resource.Finalizer
+
+			if fin, err = resource.OwnedFinalizer(ctrl.Name(), sentenceRes.String()); err != nil {
+				return err
+			}
+
 			switch strRes.Metadata().Phase() {
 			case resource.PhaseRunning:
-				if err = r.AddFinalizer(ctx, strRes.Metadata(), sentenceRes.String()); err != nil {
+				if err = r.AddFinalizer(ctx, strRes.Metadata(), fin); err != nil {
 					return fmt.Errorf("error adding finalizer: %w", err)
 				}
 
@@ -179,7 +191,7 @@ func (ctrl *StrToSentenceController) Run(ctx context.Context, r controller.Runti
 				ready, err := r.Teardown(ctx, sentenceRes.Metadata())
 				if err != nil {
 					if state.IsNotFoundError(err) {
-						if err = r.RemoveFinalizer(ctx, strRes.Metadata(), sentenceRes.String()); err != nil {
+						if err = r.RemoveFinalizer(ctx, strRes.Metadata(), fin); err != nil {
 							return fmt.Errorf("error removing finalizer (sentence controller): %w", err)
 						}
 
@@ -200,7 +212,7 @@ func (ctrl *StrToSentenceController) Run(ctx context.Context, r controller.Runti
 					return fmt.Errorf("error destroying: %w", err)
 				}
 
-				if err = r.RemoveFinalizer(ctx, strRes.Metadata(), sentenceRes.String()); err != nil {
+				if err = r.RemoveFinalizer(ctx, strRes.Metadata(), fin); err != nil {
 					return fmt.Errorf("error removing finalizer (sentence controller): %w", err)
 				}
 			}
@@ -303,3 +315,58 @@ func (ctrl *FailingController) Run(ctx context.Context, r controller.Runtime, lo
 
 	return fmt.Errorf("failing here")
 }
+
+// FinalizerController adds the finalizer to the IntResources and reports the result.
+type FinalizerController struct {
+	ControllerName  string
+	SourceNamespace resource.Namespace
+	TargetNamespace resource.Namespace
+	Finalizer       resource.Finalizer
+	ErrCh           chan<- error
+}
+
+// Name implements controller.Controller interface.
+func (ctrl *FinalizerController) Name() string {
+	return ctrl.ControllerName
+}
+
+// ManagedResources implements controller.Controller interface.
+func (ctrl *FinalizerController) ManagedResources() (resource.Namespace, resource.Type) {
+	return ctrl.TargetNamespace, StrResourceType
+}
+
+// Run implements controller.Controller interface.
+func (ctrl *FinalizerController) Run(ctx context.Context, r controller.Runtime, logger *log.Logger) error {
+	if err := r.UpdateDependencies([]controller.Dependency{
+		{
+			Namespace: ctrl.SourceNamespace,
+			Type:      IntResourceType,
+			Kind:      controller.DependencyStrong,
+		},
+	}); err != nil {
+		return fmt.Errorf("error setting up dependencies: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-r.EventCh():
+	}
+
+	intList, err := r.List(ctx, resource.NewMetadata(ctrl.SourceNamespace, IntResourceType, "", resource.VersionUndefined))
+	if err != nil {
+		return fmt.Errorf("error listing objects: %w", err)
+	}
+
+	for _, intRes := range intList.Items {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ctrl.ErrCh <- r.AddFinalizer(ctx, intRes.Metadata(), ctrl.Finalizer):
+		}
+	}
+
+	<-ctx.Done()
+
+	return nil
+}
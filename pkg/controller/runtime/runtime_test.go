@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/talos-systems/go-retry/retry"
 	"go.uber.org/goleak"
 
+	"github.com/talos-systems/os-runtime/pkg/controller"
 	"github.com/talos-systems/os-runtime/pkg/controller/runtime"
 	"github.com/talos-systems/os-runtime/pkg/resource"
 	"github.com/talos-systems/os-runtime/pkg/state"
@@ -174,6 +176,15 @@ func (suite *RuntimeSuite) TestIntToStrControllers() {
 	suite.Assert().NoError(retry.Constant(10*time.Second, retry.WithUnits(10*time.Millisecond)).
 		Retry(suite.assertStrObjects("default", StrResourceType, []string{"one", "two", "three"}, []string{"1", "2", "33"})))
 
+	// resources are created by the controller with the version as passed, and bumped on each update
+	for id, version := range map[resource.ID]string{"one": "1", "three": "2"} {
+		var str resource.Resource
+
+		str, err = suite.state.Get(suite.ctx, NewStrResource("default", id, "").Metadata())
+		suite.Require().NoError(err)
+		suite.Assert().Equal(version, str.Metadata().Version().String(), id)
+	}
+
 	ready, err := suite.state.Teardown(suite.ctx, three.Metadata())
 	suite.Assert().NoError(err)
 	suite.Assert().False(ready)
@@ -187,6 +198,48 @@ func (suite *RuntimeSuite) TestIntToStrControllers() {
 	suite.Assert().NoError(suite.state.Destroy(suite.ctx, three.Metadata()))
 }
 
+func (suite *RuntimeSuite) TestControllerFinalizers() {
+	owned, err := resource.OwnedFinalizer("OwnedFinalizerController", "int")
+	suite.Require().NoError(err)
+
+	foreign, err := resource.OwnedFinalizer("IntToStrController", "int")
+	suite.Require().NoError(err)
+
+	one := NewIntResource("default", "one", 1)
+	suite.Require().NoError(suite.state.Create(suite.ctx, one))
+
+	errChs := map[string]chan error{}
+
+	for name, fin := range map[string]resource.Finalizer{
+		"UnownedFinalizerController": "int",
+		"ForeignFinalizerController": foreign,
+		"OwnedFinalizerController":   owned,
+	} {
+		errChs[name] = make(chan error, 1)
+
+		suite.Require().NoError(suite.runtime.RegisterController(&FinalizerController{
+			ControllerName:  name,
+			SourceNamespace: "default",
+			TargetNamespace: strings.ToLower(name),
+			Finalizer:       fin,
+			ErrCh:           errChs[name],
+		}))
+	}
+
+	suite.startRuntime()
+
+	// finalizers are never renamed by the runtime
+	suite.Assert().EqualError(<-errChs["UnownedFinalizerController"],
+		`attempt to change finalizer "int" for resource "default"/"test/int" not owned by controller "UnownedFinalizerController", see resource.OwnedFinalizer`)
+	suite.Assert().EqualError(<-errChs["ForeignFinalizerController"],
+		`attempt to change finalizer "@IntToStrController:int" for resource "default"/"test/int" owned by "IntToStrController" by controller "ForeignFinalizerController"`)
+	suite.Assert().NoError(<-errChs["OwnedFinalizerController"])
+
+	r, err := suite.state.Get(suite.ctx, one.Metadata())
+	suite.Require().NoError(err)
+	suite.Assert().Equal(resource.Finalizers{owned}, *r.Metadata().Finalizers())
+}
+
 func (suite *RuntimeSuite) TestIntToStrToSentenceControllers() {
 	suite.Require().NoError(suite.runtime.RegisterController(&IntToStrController{
 		SourceNamespace: "ints",
@@ -273,6 +326,25 @@ func (suite *RuntimeSuite) TestFailingController() {
 		Retry(suite.assertIntObjects("target", IntResourceType, []string{"0", "1"}, []int{0, 1})))
 }
 
+type renamedController struct {
+	controller.Controller
+
+	name string
+}
+
+func (ctrl renamedController) Name() string {
+	return ctrl.name
+}
+
+func (suite *RuntimeSuite) TestInvalidControllerName() {
+	// controller name is used as the finalizer owner
+	err := suite.runtime.RegisterController(renamedController{&IntToStrController{}, "ns:IntToStrController"})
+	suite.Assert().EqualError(err, `invalid controller name "ns:IntToStrController": finalizer owner "ns:IntToStrController" contains invalid character ":"`)
+
+	err = suite.runtime.RegisterController(renamedController{&IntToStrController{}, ""})
+	suite.Assert().Error(err)
+}
+
 func TestRuntime(t *testing.T) {
 	t.Parallel()
 
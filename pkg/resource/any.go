@@ -5,6 +5,8 @@
 package resource
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 
 	"gopkg.in/yaml.v3"
@@ -23,9 +25,17 @@ type anySpec struct {
 }
 
 func (s anySpec) MarshalYAML() (interface{}, error) {
+	if len(s.doc.Content) == 0 {
+		return nil, nil
+	}
+
 	return s.doc.Content[0], nil
 }
 
+func (s anySpec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.value)
+}
+
 // SpecProto is a protobuf interface of resource spec.
 type SpecProto interface {
 	GetYaml() []byte
@@ -40,16 +50,9 @@ func NewAnyFromProto(protoMd MetadataProto, protoSpec SpecProto) (*Any, error) {
 
 	any := &Any{
 		md: md,
-		spec: anySpec{
-			yaml: protoSpec.GetYaml(),
-		},
 	}
 
-	if err = yaml.Unmarshal(any.spec.yaml, &any.spec.value); err != nil {
-		return nil, err
-	}
-
-	if err = yaml.Unmarshal(any.spec.yaml, &any.spec.doc); err != nil {
+	if err = any.UnmarshalSpecYAML(protoSpec.GetYaml()); err != nil {
 		return nil, err
 	}
 
@@ -71,6 +74,39 @@ func (a *Any) Value() interface{} {
 	return a.spec.value
 }
 
+// UnmarshalSpecYAML implements resource.SpecUnmarshaler.
+func (a *Any) UnmarshalSpecYAML(data []byte) error {
+	spec := anySpec{
+		yaml: data,
+	}
+
+	if err := yaml.Unmarshal(spec.yaml, &spec.value); err != nil {
+		return err
+	}
+
+	if err := yaml.Unmarshal(spec.yaml, &spec.doc); err != nil {
+		return err
+	}
+
+	a.spec = spec
+
+	return nil
+}
+
+// SpecHash implements resource.SpecHasher.
+//
+// Hash is computed over the canonical YAML representation of the decoded value.
+func (a *Any) SpecHash() []byte {
+	canonical, err := yaml.Marshal(a.spec.value)
+	if err != nil {
+		canonical = a.spec.yaml
+	}
+
+	hash := sha256.Sum256(canonical)
+
+	return hash[:]
+}
+
 func (a *Any) String() string {
 	return fmt.Sprintf("Any(%s)", a.md)
 }
@@ -78,7 +114,7 @@ func (a *Any) String() string {
 // DeepCopy implements resource.Resource.
 func (a *Any) DeepCopy() Resource {
 	return &Any{
-		md:   a.md,
+		md:   a.md.Copy(),
 		spec: a.spec,
 	}
 }
@@ -5,6 +5,7 @@
 package resource_test
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -56,3 +57,34 @@ spec:
     something: [a, b, c]
 		`)+"\n", string(out))
 }
+
+func TestAnyMarshalJSON(t *testing.T) {
+	r, err := resource.NewAnyFromProto(&protoMd{}, &protoSpec{})
+	assert.NoError(t, err)
+
+	out, err := resource.MarshalJSON(r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `{"metadata":{"namespace":"default","type":"type","id":"aaa","version":"1","phase":"running","finalizers":["resource1","resource2"]},"spec":{"something":["a","b","c"],"value":"xyz"}}`, string(out))
+
+	var decoded struct {
+		Metadata resource.Metadata `json:"metadata"`
+		Spec     interface{}       `json:"spec"`
+	}
+
+	assert.NoError(t, json.Unmarshal(out, &decoded))
+	assert.True(t, r.Metadata().Equal(decoded.Metadata))
+	assert.Equal(t, r.Value(), decoded.Spec)
+}
+
+func TestAnyDeepCopy(t *testing.T) {
+	t.Parallel()
+
+	r, err := resource.NewAnyFromProto(&protoMd{}, &protoSpec{})
+	assert.NoError(t, err)
+
+	rCopy := r.DeepCopy()
+	(*rCopy.Metadata().Finalizers())[0] = "resource3"
+
+	assert.Equal(t, resource.Finalizers{"resource1", "resource2"}, *r.Metadata().Finalizers())
+}
@@ -4,11 +4,72 @@
 
 package resource
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Finalizer is a free-form string which blocks resource destruction.
 //
 // Resource can't be destroyed until all the finalizers are cleared.
+// Finalizer might record the identity of its owner, see OwnedFinalizer.
 type Finalizer = string
 
+const (
+	ownedFinalizerPrefix    = "@"
+	finalizerOwnerSeparator = ":"
+)
+
+// OwnedFinalizer builds a finalizer which records the identity of the owner (controller or client).
+//
+// Owned finalizers are encoded as "@owner:name", and can be only added or removed by the owner.
+// Owner should be valid, see ValidateOwner.
+//
+// Finalizers which don't start with '@' are never owned, so the finalizers stored before
+// the ownership was introduced (e.g. "foo:bar") keep their meaning. Stored finalizers which
+// happen to start with '@' are read as owned, and should be migrated before upgrading:
+// removed and added back either without the '@' prefix, or with OwnedFinalizer by their owner.
+func OwnedFinalizer(owner, name string) (Finalizer, error) {
+	if err := ValidateOwner(owner); err != nil {
+		return "", err
+	}
+
+	return ownedFinalizerPrefix + owner + finalizerOwnerSeparator + name, nil
+}
+
+// ValidateOwner verifies that the owner can be recorded in the finalizer.
+//
+// Owner should be non-empty and might contain only letters, digits and '.', '_', '-', '/' characters.
+func ValidateOwner(owner string) error {
+	if owner == "" {
+		return fmt.Errorf("finalizer owner is empty")
+	}
+
+	if idx := strings.IndexFunc(owner, invalidOwnerRune); idx != -1 {
+		return fmt.Errorf("finalizer owner %q contains invalid character %q", owner, owner[idx:idx+1])
+	}
+
+	return nil
+}
+
+// FinalizerOwner returns the owner and the name of the finalizer built with OwnedFinalizer.
+//
+// For finalizers without an owner, owner is empty and name is the finalizer itself.
+func FinalizerOwner(fin Finalizer) (owner, name string) {
+	if !strings.HasPrefix(fin, ownedFinalizerPrefix) {
+		return "", fin
+	}
+
+	encoded := fin[len(ownedFinalizerPrefix):]
+
+	idx := strings.Index(encoded, finalizerOwnerSeparator)
+	if idx <= 0 || strings.IndexFunc(encoded[:idx], invalidOwnerRune) != -1 {
+		return "", fin
+	}
+
+	return encoded[:idx], encoded[idx+len(finalizerOwnerSeparator):]
+}
+
 // Finalizers is a set of Finalizer's with methods to add/remove items.
 type Finalizers []Finalizer
 
@@ -48,3 +109,14 @@ func (fins *Finalizers) Remove(fin Finalizer) (removed bool) {
 func (fins Finalizers) Empty() bool {
 	return len(fins) == 0
 }
+
+func invalidOwnerRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	case r == '.', r == '_', r == '-', r == '/':
+		return false
+	default:
+		return true
+	}
+}
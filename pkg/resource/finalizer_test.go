@@ -45,3 +45,30 @@ func TestFinalizers(t *testing.T) {
 	assert.True(t, fins.Add(C))
 	assert.True(t, fins.Remove(C))
 }
+
+func TestOwnedFinalizer(t *testing.T) {
+	fin, err := resource.OwnedFinalizer("IntToStrController", "str")
+	assert.NoError(t, err)
+	assert.Equal(t, "@IntToStrController:str", fin)
+
+	owner, name := resource.FinalizerOwner(fin)
+	assert.Equal(t, "IntToStrController", owner)
+	assert.Equal(t, "str", name)
+
+	// legacy finalizers are not owned
+	for _, fin := range []resource.Finalizer{"A", ":a", "foo:bar", "IntToStrController:str", "@:a", "@a b:c", `StrResource("a" -> "b:c")`} {
+		owner, name = resource.FinalizerOwner(fin)
+		assert.Empty(t, owner, fin)
+		assert.Equal(t, fin, name)
+	}
+}
+
+func TestOwnedFinalizerInvalidOwner(t *testing.T) {
+	for _, owner := range []string{"", "ns:IntToStrController", "Int To Str"} {
+		_, err := resource.OwnedFinalizer(owner, "str")
+		assert.Error(t, err, owner)
+		assert.Error(t, resource.ValidateOwner(owner), owner)
+	}
+
+	assert.EqualError(t, resource.ValidateOwner("ns:ctrl"), `finalizer owner "ns:ctrl" contains invalid character ":"`)
+}
@@ -0,0 +1,87 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package resource
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SpecEqualer is implemented by resources which can compare specs faster than reflect.DeepEqual.
+//
+// Resources which consider specs with different contents equal should implement SpecHasher
+// consistently with SpecEqual, so that Fingerprint matches Equal.
+type SpecEqualer interface {
+	// SpecEqual compares resource spec with the spec of other resource of the same type.
+	//
+	// Other spec might have a different Go type (e.g. spec of resource.Any), in that case SpecEqual should return false.
+	SpecEqual(other interface{}) bool
+}
+
+// SpecHasher is implemented by resources which provide canonical hash of the spec contents.
+//
+// Specs with equal contents should have equal hashes.
+type SpecHasher interface {
+	SpecHash() []byte
+}
+
+func specEqual(r1, r2 Resource) bool {
+	if r1.Metadata().Type() != r2.Metadata().Type() {
+		return false
+	}
+
+	if equaler, ok := r1.(SpecEqualer); ok {
+		return equaler.SpecEqual(r2.Spec())
+	}
+
+	hasher1, ok1 := r1.(SpecHasher)
+	hasher2, ok2 := r2.(SpecHasher)
+
+	if ok1 && ok2 {
+		return bytes.Equal(hasher1.SpecHash(), hasher2.SpecHash())
+	}
+
+	return reflect.DeepEqual(r1.Spec(), r2.Spec())
+}
+
+// Fingerprint computes stable hash of the resource contents.
+//
+// Fingerprint covers resource namespace, type, ID, phase, finalizers and spec,
+// but not the version, so the resources with same contents have the same fingerprint.
+// Spec is hashed via SpecHasher if available, otherwise via its YAML representation.
+func Fingerprint(r Resource) (string, error) {
+	md := r.Metadata()
+
+	fins := append(Finalizers(nil), md.fins...)
+	sort.Strings(fins)
+
+	hash := sha256.New()
+
+	fmt.Fprintf(hash, "%q\n%q\n%q\n%q\n%q\n", md.ns, md.typ, md.id, md.phase, fins)
+
+	if !IsTombstone(r) {
+		if hasher, ok := r.(SpecHasher); ok {
+			hash.Write(hasher.SpecHash()) //nolint: errcheck
+		} else {
+			encoder := yaml.NewEncoder(hash)
+
+			if err := encoder.Encode(r.Spec()); err != nil {
+				return "", fmt.Errorf("error encoding spec of %s: %w", r, err)
+			}
+
+			if err := encoder.Close(); err != nil {
+				return "", fmt.Errorf("error encoding spec of %s: %w", r, err)
+			}
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,139 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package resource_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+)
+
+type formattedSpec struct {
+	yaml string
+}
+
+func (s *formattedSpec) GetYaml() []byte {
+	return []byte(s.yaml)
+}
+
+type equalerResource struct {
+	md    resource.Metadata
+	value []int
+}
+
+func (r *equalerResource) Metadata() *resource.Metadata {
+	return &r.md
+}
+
+func (r *equalerResource) Spec() interface{} {
+	return r.value
+}
+
+func (r *equalerResource) String() string {
+	return "equalerResource"
+}
+
+func (r *equalerResource) DeepCopy() resource.Resource {
+	return &equalerResource{
+		md:    r.md,
+		value: append([]int(nil), r.value...),
+	}
+}
+
+func (r *equalerResource) SpecEqual(other interface{}) bool {
+	value, ok := other.([]int)
+
+	return ok && len(r.value) == len(value)
+}
+
+func (r *equalerResource) SpecHash() []byte {
+	return []byte(strconv.Itoa(len(r.value)))
+}
+
+func TestEqualSpecHasher(t *testing.T) {
+	t.Parallel()
+
+	r1, err := resource.NewAnyFromProto(&protoMd{}, &formattedSpec{"a: 1\nb: [x, y]\n"})
+	require.NoError(t, err)
+
+	r2, err := resource.NewAnyFromProto(&protoMd{}, &formattedSpec{"b:\n  - x\n  - y\na:   1\n"})
+	require.NoError(t, err)
+
+	r3, err := resource.NewAnyFromProto(&protoMd{}, &formattedSpec{"a: 2\nb: [x, y]\n"})
+	require.NoError(t, err)
+
+	assert.True(t, resource.Equal(r1, r2))
+	assert.False(t, resource.Equal(r1, r3))
+
+	fp1, err := resource.Fingerprint(r1)
+	require.NoError(t, err)
+
+	fp2, err := resource.Fingerprint(r2)
+	require.NoError(t, err)
+
+	fp3, err := resource.Fingerprint(r3)
+	require.NoError(t, err)
+
+	assert.Equal(t, fp1, fp2)
+	assert.NotEqual(t, fp1, fp3)
+
+	r2.Metadata().BumpVersion()
+
+	fp2, err = resource.Fingerprint(r2)
+	require.NoError(t, err)
+
+	assert.Equal(t, fp1, fp2)
+
+	r2.Metadata().Finalizers().Add("A")
+
+	fp2, err = resource.Fingerprint(r2)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, fp1, fp2)
+}
+
+func TestEqualSpecEqualer(t *testing.T) {
+	t.Parallel()
+
+	md := resource.NewMetadata("default", "type", "aaa", resource.VersionUndefined)
+
+	r1 := &equalerResource{md: md, value: []int{1, 2}}
+	r2 := &equalerResource{md: md, value: []int{3, 4}}
+	r3 := &equalerResource{md: md, value: []int{1}}
+
+	assert.True(t, resource.Equal(r1, r2))
+	assert.False(t, resource.Equal(r1, r3))
+
+	fp1, err := resource.Fingerprint(r1)
+	require.NoError(t, err)
+
+	fp2, err := resource.Fingerprint(r2)
+	require.NoError(t, err)
+
+	fp3, err := resource.Fingerprint(r3)
+	require.NoError(t, err)
+
+	assert.Equal(t, fp1, fp2)
+	assert.NotEqual(t, fp1, fp3)
+
+	// foreign specs are never equal
+	any, err := resource.NewAnyFromProto(&protoMd{}, &formattedSpec{"[1, 2]\n"})
+	require.NoError(t, err)
+
+	*any.Metadata() = md
+
+	assert.False(t, resource.Equal(r1, any))
+
+	anyMd := resource.NewMetadata("default", "other", "aaa", resource.VersionUndefined)
+	assert.False(t, resource.Equal(r1, &equalerResource{md: anyMd, value: []int{1, 2}}))
+
+	fp, err := resource.Fingerprint(resource.NewTombstone(md))
+	require.NoError(t, err)
+	assert.NotEmpty(t, fp)
+}
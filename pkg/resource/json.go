@@ -0,0 +1,178 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalJSON implements json.Marshaler interface.
+//
+// Version is always encoded as a string, as in the protobuf representation.
+func (v Version) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+//
+// Version might be encoded either as a string or as a number.
+func (v *Version) UnmarshalJSON(b []byte) error {
+	var str string
+
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &str); err != nil {
+			return err
+		}
+	} else {
+		str = string(b)
+	}
+
+	ver, err := ParseVersion(str)
+	if err != nil {
+		return err
+	}
+
+	*v = ver
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (ph Phase) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ph.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (ph *Phase) UnmarshalJSON(b []byte) error {
+	var str string
+
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+
+	phase, err := ParsePhase(str)
+	if err != nil {
+		return err
+	}
+
+	*ph = phase
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (fins Finalizers) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]Finalizer(append(Finalizers{}, fins...)))
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (fins *Finalizers) UnmarshalJSON(b []byte) error {
+	var list []Finalizer
+
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+
+	*fins = nil
+
+	for _, fin := range list {
+		fins.Add(fin)
+	}
+
+	return nil
+}
+
+type metadataJSON struct {
+	Namespace  Namespace   `json:"namespace"`
+	Type       Type        `json:"type"`
+	ID         ID          `json:"id"`
+	Version    Version     `json:"version"`
+	Phase      Phase       `json:"phase"`
+	Finalizers *Finalizers `json:"finalizers,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+//
+// JSON representation matches the YAML one.
+func (md Metadata) MarshalJSON() ([]byte, error) {
+	out := metadataJSON{
+		Namespace: md.ns,
+		Type:      md.typ,
+		ID:        md.id,
+		Version:   md.ver,
+		Phase:     md.phase,
+	}
+
+	if !md.fins.Empty() {
+		out.Finalizers = &md.fins
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (md *Metadata) UnmarshalJSON(b []byte) error {
+	in := metadataJSON{
+		Finalizers: &Finalizers{},
+	}
+
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+
+	*md = NewMetadata(in.Namespace, in.Type, in.ID, in.Version)
+	md.phase = in.Phase
+
+	if !in.Finalizers.Empty() {
+		md.fins = *in.Finalizers
+	}
+
+	return nil
+}
+
+// MarshalJSON marshals resource to JSON definition.
+//
+// JSON definition is equivalent to the YAML one produced by MarshalYAML:
+// if the spec doesn't implement json.Marshaler, it is converted via YAML representation,
+// so that YAML field names are used.
+func MarshalJSON(r Resource) ([]byte, error) {
+	var spec interface{} = r.Spec()
+
+	if _, ok := spec.(json.Marshaler); !ok {
+		specYAML, err := yaml.Marshal(spec)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling spec of %s: %w", r, err)
+		}
+
+		var value interface{}
+
+		if err = yaml.Unmarshal(specYAML, &value); err != nil {
+			return nil, fmt.Errorf("error converting spec of %s: %w", r, err)
+		}
+
+		spec = value
+	}
+
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(&struct {
+		Metadata *Metadata   `json:"metadata"`
+		Spec     interface{} `json:"spec"`
+	}{
+		Metadata: r.Metadata(),
+		Spec:     spec,
+	}); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
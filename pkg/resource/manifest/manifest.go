@@ -0,0 +1,166 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package manifest decodes resources from YAML definitions produced by resource.MarshalYAML.
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+)
+
+// Registry creates empty typed resources by resource type.
+//
+// If the type is not known to the registry, resources are decoded as resource.Any.
+type Registry interface {
+	New(resource.Type) (resource.Resource, bool)
+}
+
+// Options for the decoder.
+type Options struct {
+	Registry Registry
+}
+
+// Option builds Options.
+type Option func(*Options)
+
+// WithRegistry decodes resources of the types known to the registry as typed resources.
+//
+// Typed resources should implement resource.SpecUnmarshaler.
+func WithRegistry(registry Registry) Option {
+	return func(opts *Options) {
+		opts.Registry = registry
+	}
+}
+
+// Decoder reads resources from a multi-document YAML stream.
+type Decoder struct {
+	decoder *yaml.Decoder
+	options Options
+}
+
+// NewDecoder creates a Decoder reading from r.
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	decoder := &Decoder{
+		decoder: yaml.NewDecoder(r),
+	}
+
+	for _, opt := range opts {
+		opt(&decoder.options)
+	}
+
+	return decoder
+}
+
+// Decode the next resource from the stream.
+//
+// Empty documents are skipped, io.EOF is returned at the end of the stream.
+func (decoder *Decoder) Decode() (resource.Resource, error) {
+	for {
+		var node yaml.Node
+
+		if err := decoder.decoder.Decode(&node); err != nil {
+			return nil, err
+		}
+
+		if len(node.Content) == 0 || node.Content[0].Tag == "!!null" {
+			continue
+		}
+
+		return decodeNode(node.Content[0], &decoder.options)
+	}
+}
+
+// ReadAll reads all the resources from the stream.
+func ReadAll(r io.Reader, opts ...Option) ([]resource.Resource, error) {
+	decoder := NewDecoder(r, opts...)
+
+	var result []resource.Resource
+
+	for {
+		res, err := decoder.Decode()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return result, nil
+			}
+
+			return nil, fmt.Errorf("error decoding resource #%d: %w", len(result)+1, err)
+		}
+
+		result = append(result, res)
+	}
+}
+
+// Unmarshal decodes a single resource from the YAML definition.
+func Unmarshal(data []byte, opts ...Option) (resource.Resource, error) {
+	return NewDecoder(bytes.NewReader(data), opts...).Decode()
+}
+
+func decodeNode(node *yaml.Node, options *Options) (resource.Resource, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: resource definition should be a mapping", node.Line)
+	}
+
+	var mdNode, specNode *yaml.Node
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		switch key.Value {
+		case "metadata":
+			mdNode = value
+		case "spec":
+			specNode = value
+		default:
+			return nil, fmt.Errorf("line %d: unexpected key %q", key.Line, key.Value)
+		}
+	}
+
+	if mdNode == nil {
+		return nil, fmt.Errorf("line %d: metadata is missing", node.Line)
+	}
+
+	var md resource.Metadata
+
+	if err := mdNode.Decode(&md); err != nil {
+		return nil, fmt.Errorf("line %d: error decoding metadata: %w", mdNode.Line, err)
+	}
+
+	var res resource.Resource = &resource.Any{}
+
+	if options.Registry != nil {
+		if typed, ok := options.Registry.New(md.Type()); ok {
+			res = typed
+		}
+	}
+
+	unmarshaler, ok := res.(resource.SpecUnmarshaler)
+	if !ok {
+		return nil, fmt.Errorf("resource %s doesn't support spec unmarshaling", res)
+	}
+
+	*res.Metadata() = md
+
+	var specYAML []byte
+
+	if specNode != nil {
+		var err error
+
+		if specYAML, err = yaml.Marshal(specNode); err != nil {
+			return nil, fmt.Errorf("line %d: error encoding spec: %w", specNode.Line, err)
+		}
+	}
+
+	if err := unmarshaler.UnmarshalSpecYAML(specYAML); err != nil {
+		return nil, fmt.Errorf("error decoding spec of %s: %w", md, err)
+	}
+
+	return res, nil
+}
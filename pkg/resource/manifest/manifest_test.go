@@ -0,0 +1,160 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package manifest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/manifest"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+)
+
+type registry struct{}
+
+func (registry) New(typ resource.Type) (resource.Resource, bool) {
+	if typ == meta.NamespaceType {
+		return &meta.Namespace{}, true
+	}
+
+	return nil, false
+}
+
+const stream = `
+metadata:
+    namespace: meta
+    type: Namespaces.meta.cosi.dev
+    id: user
+    version: 3
+    phase: tearingDown
+    finalizers:
+      - A
+spec:
+    description: user namespace
+---
+# empty document
+---
+metadata:
+    namespace: default
+    type: Configs.cosi.dev
+    id: config
+    version: 1
+spec:
+    values: [1, 2]
+---
+metadata:
+    namespace: default
+    type: Paths.cosi.dev
+    id: /var
+`
+
+func TestReadAll(t *testing.T) {
+	t.Parallel()
+
+	resources, err := manifest.ReadAll(strings.NewReader(stream), manifest.WithRegistry(registry{}))
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	ns, ok := resources[0].(*meta.Namespace)
+	require.True(t, ok)
+
+	assert.Equal(t, "user", ns.Metadata().ID())
+	assert.Equal(t, "3", ns.Metadata().Version().String())
+	assert.Equal(t, resource.PhaseTearingDown, ns.Metadata().Phase())
+	assert.Equal(t, resource.Finalizers{"A"}, *ns.Metadata().Finalizers())
+	assert.Equal(t, meta.NamespaceSpec{Description: "user namespace"}, ns.Spec())
+
+	config, ok := resources[1].(*resource.Any)
+	require.True(t, ok)
+
+	assert.Equal(t, resource.PhaseRunning, config.Metadata().Phase())
+	assert.Equal(t, map[string]interface{}{"values": []interface{}{1, 2}}, config.Value())
+
+	path, ok := resources[2].(*resource.Any)
+	require.True(t, ok)
+
+	assert.Equal(t, "/var", path.Metadata().ID())
+	assert.Equal(t, resource.VersionUndefined, path.Metadata().Version())
+	assert.Nil(t, path.Value())
+
+	resources, err = manifest.ReadAll(strings.NewReader(stream))
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	assert.IsType(t, &resource.Any{}, resources[0])
+}
+
+func TestRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	ns := meta.NewNamespace("user", meta.NamespaceSpec{
+		Description: "user namespace",
+	})
+	ns.Metadata().Finalizers().Add("A")
+
+	out, err := yaml.Marshal(ns)
+	require.NoError(t, err)
+
+	r, err := manifest.Unmarshal(out, manifest.WithRegistry(registry{}))
+	require.NoError(t, err)
+
+	assert.True(t, resource.Equal(ns, r))
+
+	r, err = manifest.Unmarshal(out)
+	require.NoError(t, err)
+
+	out2, err := resource.MarshalJSON(r)
+	require.NoError(t, err)
+
+	out1, err := resource.MarshalJSON(ns)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(out1), string(out2))
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name          string
+		input         string
+		expectedError string
+	}{
+		{
+			name:          "notMapping",
+			input:         "[a, b]",
+			expectedError: "line 1: resource definition should be a mapping",
+		},
+		{
+			name:          "unexpectedKey",
+			input:         "metadata: {}\nstatus: {}",
+			expectedError: "line 2: unexpected key \"status\"",
+		},
+		{
+			name:          "noMetadata",
+			input:         "spec: {}",
+			expectedError: "line 1: metadata is missing",
+		},
+		{
+			name:          "badPhase",
+			input:         "metadata: {phase: unknown}",
+			expectedError: "line 1: error decoding metadata: uknown phase: unknown",
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := manifest.Unmarshal([]byte(tt.input))
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
@@ -6,60 +6,56 @@ package meta
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/typed"
 )
 
 // NamespaceType is the type of Namespace.
 const NamespaceType = resource.Type("Namespaces.meta.cosi.dev")
 
 // Namespace provides metadata about namespaces.
-type Namespace struct {
-	md   resource.Metadata
-	spec NamespaceSpec
-}
+type Namespace = typed.Resource[NamespaceSpec, NamespaceExtension]
 
 // NamespaceSpec provides Namespace definition.
 type NamespaceSpec struct {
 	Description string `yaml:"description"`
 }
 
-// NewNamespace initializes a Namespace resource.
-func NewNamespace(id resource.ID, spec NamespaceSpec) *Namespace {
-	r := &Namespace{
-		md:   resource.NewMetadata(NamespaceName, NamespaceType, id, resource.VersionUndefined),
-		spec: spec,
-	}
+// DeepCopy generates a deep copy of NamespaceSpec.
+func (spec NamespaceSpec) DeepCopy() NamespaceSpec {
+	return spec
+}
 
-	r.md.BumpVersion()
+var namespaceRegexp = regexp.MustCompile(`^[a-z][a-z0-9-]*(\.[a-z][a-z0-9-]*)*$`)
 
-	return r
-}
+// ValidateNamespaceName checks that the namespace name is well-formed.
+func ValidateNamespaceName(ns resource.Namespace) error {
+	if ns == "" {
+		return fmt.Errorf("namespace name is empty")
+	}
 
-// Metadata implements resource.Resource.
-func (r *Namespace) Metadata() *resource.Metadata {
-	return &r.md
-}
+	if !namespaceRegexp.MatchString(ns) {
+		return fmt.Errorf("namespace name %q doesn't match %q", ns, namespaceRegexp.String())
+	}
 
-// Spec implements resource.Resource.
-func (r *Namespace) Spec() interface{} {
-	return r.spec
+	return nil
 }
 
-func (r *Namespace) String() string {
-	return fmt.Sprintf("Namespace(%q)", r.md.ID())
-}
+// NewNamespace initializes a Namespace resource.
+func NewNamespace(id resource.ID, spec NamespaceSpec) *Namespace {
+	md := resource.NewMetadata(NamespaceName, NamespaceType, id, resource.VersionUndefined)
+	md.BumpVersion()
 
-// DeepCopy implements resource.Resource.
-func (r *Namespace) DeepCopy() resource.Resource {
-	return &Namespace{
-		md:   r.md,
-		spec: r.spec,
-	}
+	return typed.NewResource[NamespaceSpec, NamespaceExtension](md, spec)
 }
 
-// ResourceDefinition implements core.ResourceDefinitionProvider interface.
-func (r *Namespace) ResourceDefinition() ResourceDefinitionSpec {
+// NamespaceExtension provides auxiliary methods for Namespace.
+type NamespaceExtension struct{}
+
+// ResourceDefinition implements typed.Extension interface.
+func (NamespaceExtension) ResourceDefinition() ResourceDefinitionSpec {
 	return ResourceDefinitionSpec{
 		Type:             NamespaceType,
 		DefaultNamespace: NamespaceName,
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/talos-systems/os-runtime/pkg/resource"
 	"github.com/talos-systems/os-runtime/pkg/resource/meta"
 )
 
@@ -88,6 +89,41 @@ func TestRDSpecValidation(t *testing.T) {
 			},
 			expectedError: "name should be plural",
 		},
+		{
+			name: "versionFormat",
+			spec: meta.ResourceDefinitionSpec{
+				Type:           "Tests.cosi.dev",
+				Versions:       []string{"1"},
+				StorageVersion: "1",
+			},
+			expectedError: "version \"1\" doesn't match \"^v[0-9]+((alpha|beta)[0-9]+)?$\"",
+		},
+		{
+			name: "duplicateVersion",
+			spec: meta.ResourceDefinitionSpec{
+				Type:           "Tests.cosi.dev",
+				Versions:       []string{"v1", "v1"},
+				StorageVersion: "v1",
+			},
+			expectedError: "version \"v1\" is declared more than once",
+		},
+		{
+			name: "noStorageVersion",
+			spec: meta.ResourceDefinitionSpec{
+				Type:     "Tests.cosi.dev",
+				Versions: []string{"v1alpha1", "v1"},
+			},
+			expectedError: "storage version is not set",
+		},
+		{
+			name: "unknownStorageVersion",
+			spec: meta.ResourceDefinitionSpec{
+				Type:           "Tests.cosi.dev",
+				Versions:       []string{"v1alpha1", "v1"},
+				StorageVersion: "v2",
+			},
+			expectedError: "storage version \"v2\" is not declared",
+		},
 	} {
 		tt := tt
 
@@ -96,3 +132,14 @@ func TestRDSpecValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshalJSON(t *testing.T) {
+	r := meta.NewNamespace("user", meta.NamespaceSpec{
+		Description: "<user>",
+	})
+
+	out, err := resource.MarshalJSON(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"metadata":{"namespace":"meta","type":"Namespaces.meta.cosi.dev","id":"user","version":"1","phase":"running"},"spec":{"description":"<user>"}}`, string(out))
+}
@@ -0,0 +1,180 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package spec provides specs of core metadata resources.
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	pluralize "github.com/gertd/go-pluralize"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+)
+
+// PrintColumn describes extra columns to print for the resources.
+type PrintColumn struct {
+	Name     string `yaml:"name"`
+	JSONPath string `yaml:"jsonPath"`
+}
+
+// ResourceDefinitionSpec provides ResourceDefinition definition.
+type ResourceDefinitionSpec struct {
+	Type         resource.Type   `yaml:"type"`
+	DisplayType  string          `yaml:"displayType"`
+	Aliases      []resource.Type `yaml:"aliases"`
+	PrintColumns []PrintColumn   `yaml:"printColumns"`
+
+	DefaultNamespace resource.Namespace `yaml:"defaultNamespace"`
+
+	// Versions lists spec versions of the resource, StorageVersion is the version
+	// resources are persisted in.
+	Versions       []string `yaml:"versions,omitempty"`
+	StorageVersion string   `yaml:"storageVersion,omitempty"`
+
+	// Sensitive resources have their specs encrypted at rest.
+	Sensitive bool `yaml:"sensitive,omitempty"`
+}
+
+// DeepCopy generates a deep copy of ResourceDefinitionSpec.
+func (spec ResourceDefinitionSpec) DeepCopy() ResourceDefinitionSpec {
+	if spec.Aliases != nil {
+		spec.Aliases = append([]resource.Type{}, spec.Aliases...)
+	}
+
+	if spec.PrintColumns != nil {
+		spec.PrintColumns = append([]PrintColumn{}, spec.PrintColumns...)
+	}
+
+	if spec.Versions != nil {
+		spec.Versions = append([]string{}, spec.Versions...)
+	}
+
+	return spec
+}
+
+// ID computes id of the resource definition.
+func (spec *ResourceDefinitionSpec) ID() resource.ID {
+	return strings.ToLower(spec.Type)
+}
+
+var (
+	nameRegexp      = regexp.MustCompile(`^[A-Z][A-Za-z0-9-]+$`)
+	suffixRegexp    = regexp.MustCompile(`^[a-z][a-z0-9-]+(\.[a-z][a-z0-9-]+)*$`)
+	versionRegexp   = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]+)?$`)
+	pluralizeClient = pluralize.NewClient()
+)
+
+// Fill the spec while validating any missing items.
+func (spec *ResourceDefinitionSpec) Fill() error {
+	parts := strings.SplitN(spec.Type, ".", 2)
+	if len(parts) == 1 {
+		return fmt.Errorf("missing suffix")
+	}
+
+	name, suffix := parts[0], parts[1]
+
+	if len(name) == 0 {
+		return fmt.Errorf("name is empty")
+	}
+
+	if len(suffix) == 0 {
+		return fmt.Errorf("suffix is empty")
+	}
+
+	if strings.ToLower(name) == name {
+		return fmt.Errorf("name should be in CamelCase")
+	}
+
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("name doesn't match %q", nameRegexp.String())
+	}
+
+	if !suffixRegexp.MatchString(suffix) {
+		return fmt.Errorf("suffix doesn't match %q", suffixRegexp.String())
+	}
+
+	if !pluralizeClient.IsPlural(name) {
+		return fmt.Errorf("name should be plural")
+	}
+
+	if err := spec.validateVersions(); err != nil {
+		return err
+	}
+
+	spec.DisplayType = pluralizeClient.Singular(name)
+	spec.Aliases = append(spec.Aliases, strings.ToLower(name), strings.ToLower(spec.DisplayType))
+
+	suffixElements := strings.Split(suffix, ".")
+
+	for i := 1; i < len(suffixElements); i++ {
+		spec.Aliases = append(spec.Aliases, strings.Join(append([]string{strings.ToLower(name)}, suffixElements[:i]...), "."))
+	}
+
+	upperLetters := strings.Map(func(ch rune) rune {
+		if unicode.IsUpper(ch) {
+			return ch
+		}
+
+		return -1
+	}, name)
+
+	if len(upperLetters) > 1 {
+		spec.Aliases = append(spec.Aliases, strings.ToLower(upperLetters))
+
+		if !strings.HasSuffix(upperLetters, "S") {
+			spec.Aliases = append(spec.Aliases, strings.ToLower(upperLetters+"s"))
+		}
+	}
+
+	return nil
+}
+
+// HasVersion checks whether the version is declared in the definition.
+func (spec *ResourceDefinitionSpec) HasVersion(version string) bool {
+	for _, v := range spec.Versions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (spec *ResourceDefinitionSpec) validateVersions() error {
+	if len(spec.Versions) == 0 {
+		if spec.StorageVersion != "" {
+			return fmt.Errorf("storage version %q is not declared", spec.StorageVersion)
+		}
+
+		return nil
+	}
+
+	seen := map[string]struct{}{}
+
+	for _, version := range spec.Versions {
+		if !versionRegexp.MatchString(version) {
+			return fmt.Errorf("version %q doesn't match %q", version, versionRegexp.String())
+		}
+
+		if _, ok := seen[version]; ok {
+			return fmt.Errorf("version %q is declared more than once", version)
+		}
+
+		seen[version] = struct{}{}
+	}
+
+	if spec.StorageVersion == "" {
+		return fmt.Errorf("storage version is not set")
+	}
+
+	if !spec.HasVersion(spec.StorageVersion) {
+		return fmt.Errorf("storage version %q is not declared", spec.StorageVersion)
+	}
+
+	return nil
+}
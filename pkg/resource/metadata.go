@@ -49,6 +49,10 @@ func (md Metadata) Namespace() Namespace {
 
 // Copy returns metadata copy.
 func (md Metadata) Copy() Metadata {
+	if md.fins != nil {
+		md.fins = append(make(Finalizers, 0, len(md.fins)), md.fins...)
+	}
+
 	return md
 }
 
@@ -198,6 +202,51 @@ func (md *Metadata) MarshalYAML() (interface{}, error) {
 	}, nil
 }
 
+type metadataYAML struct {
+	Namespace  Namespace   `yaml:"namespace"`
+	Type       Type        `yaml:"type"`
+	ID         ID          `yaml:"id"`
+	Version    string      `yaml:"version"`
+	Phase      string      `yaml:"phase"`
+	Finalizers []Finalizer `yaml:"finalizers"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler interface.
+func (md *Metadata) UnmarshalYAML(node *yaml.Node) error {
+	var in metadataYAML
+
+	if err := node.Decode(&in); err != nil {
+		return err
+	}
+
+	if in.Version == "" {
+		in.Version = undefinedVersion
+	}
+
+	if in.Phase == "" {
+		in.Phase = strPhaseRunning
+	}
+
+	ver, err := ParseVersion(in.Version)
+	if err != nil {
+		return err
+	}
+
+	phase, err := ParsePhase(in.Phase)
+	if err != nil {
+		return err
+	}
+
+	*md = NewMetadata(in.Namespace, in.Type, in.ID, ver)
+	md.phase = phase
+
+	for _, fin := range in.Finalizers {
+		md.fins.Add(fin)
+	}
+
+	return nil
+}
+
 // MetadataProto is an interface for protobuf serialization of Metadata.
 type MetadataProto interface {
 	GetNamespace() string
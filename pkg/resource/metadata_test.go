@@ -5,6 +5,7 @@
 package resource_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -143,3 +144,54 @@ func TestNewMedataFromProto(t *testing.T) {
 
 	assert.True(t, md.Equal(other))
 }
+
+func TestMetadataJSON(t *testing.T) {
+	t.Parallel()
+
+	md := resource.NewMetadata("default", "type", "aaa", resource.VersionUndefined)
+	md.BumpVersion()
+
+	out, err := json.Marshal(md)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"namespace":"default","type":"type","id":"aaa","version":"1","phase":"running"}`, string(out))
+
+	md.Finalizers().Add("\"resource1")
+	md.Finalizers().Add("resource2")
+	md.SetPhase(resource.PhaseTearingDown)
+
+	out, err = json.Marshal(&md)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"namespace":"default","type":"type","id":"aaa","version":"1","phase":"tearingDown","finalizers":["\"resource1","resource2"]}`, string(out))
+
+	var decoded resource.Metadata
+
+	assert.NoError(t, json.Unmarshal(out, &decoded))
+	assert.True(t, md.Equal(decoded))
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"namespace":"default","type":"type","id":"aaa","version":2,"phase":"running"}`), &decoded))
+	assert.Equal(t, "2", decoded.Version().String())
+	assert.True(t, decoded.Finalizers().Empty())
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"version":"undefined","phase":"running"}`), &decoded))
+	assert.Equal(t, resource.VersionUndefined, decoded.Version())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"phase":"unknown"}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"version":"abc"}`), &decoded))
+}
+
+func TestMetadataUnmarshalYAML(t *testing.T) {
+	t.Parallel()
+
+	md := resource.NewMetadata("default", "type", "aaa", resource.VersionUndefined)
+	md.BumpVersion()
+	md.Finalizers().Add("\"resource1")
+	md.Finalizers().Add("resource2")
+
+	out, err := yaml.Marshal(&md)
+	assert.NoError(t, err)
+
+	var decoded resource.Metadata
+
+	assert.NoError(t, yaml.Unmarshal(out, &decoded))
+	assert.True(t, md.Equal(decoded))
+}
@@ -0,0 +1,94 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package patch implements partial updates of resource specs.
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+)
+
+// Type describes the format of the patch.
+type Type int
+
+// Patch types.
+const (
+	// JSON merge patch as defined in RFC 7386.
+	MergeJSON Type = iota
+	// YAML patch merging lists of mappings by the key.
+	StrategicYAML
+)
+
+func (typ Type) String() string {
+	switch typ {
+	case MergeJSON:
+		return "merge"
+	case StrategicYAML:
+		return "strategic"
+	default:
+		return fmt.Sprintf("Type(%d)", int(typ))
+	}
+}
+
+// Patch is a partial update of the resource spec.
+type Patch struct {
+	Type Type
+	Data []byte
+}
+
+// Apply the patch to the resource spec.
+//
+// Resource should implement resource.SpecUnmarshaler.
+func Apply(r resource.Resource, p Patch) error {
+	unmarshaler, ok := r.(resource.SpecUnmarshaler)
+	if !ok {
+		return fmt.Errorf("resource %s doesn't support spec unmarshaling", r)
+	}
+
+	specYAML, err := yaml.Marshal(r.Spec())
+	if err != nil {
+		return fmt.Errorf("error marshaling spec: %w", err)
+	}
+
+	var doc interface{}
+
+	if err = yaml.Unmarshal(specYAML, &doc); err != nil {
+		return fmt.Errorf("error unmarshaling spec: %w", err)
+	}
+
+	var patch interface{}
+
+	switch p.Type {
+	case MergeJSON:
+		if err = json.Unmarshal(p.Data, &patch); err != nil {
+			return fmt.Errorf("error decoding merge patch: %w", err)
+		}
+
+		doc = mergeJSON(doc, patch)
+	case StrategicYAML:
+		if err = yaml.Unmarshal(p.Data, &patch); err != nil {
+			return fmt.Errorf("error decoding strategic patch: %w", err)
+		}
+
+		doc = mergeStrategic(doc, patch)
+	default:
+		return fmt.Errorf("unsupported patch type %d", p.Type)
+	}
+
+	specYAML, err = yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("error marshaling patched spec: %w", err)
+	}
+
+	if err = unmarshaler.UnmarshalSpecYAML(specYAML); err != nil {
+		return fmt.Errorf("error updating resource %s spec: %w", r, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package patch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/resource/patch"
+)
+
+type protoMd struct{}
+
+func (protoMd) GetNamespace() string {
+	return "default"
+}
+
+func (protoMd) GetType() string {
+	return "type"
+}
+
+//nolint: golint, stylecheck
+func (protoMd) GetId() string {
+	return "aaa"
+}
+
+func (protoMd) GetVersion() string {
+	return "1"
+}
+
+func (protoMd) GetPhase() string {
+	return resource.PhaseRunning.String()
+}
+
+func (protoMd) GetFinalizers() []string {
+	return nil
+}
+
+type protoSpec struct {
+	yaml string
+}
+
+func (s protoSpec) GetYaml() []byte {
+	return []byte(s.yaml)
+}
+
+func newAny(t *testing.T, spec string) *resource.Any {
+	r, err := resource.NewAnyFromProto(protoMd{}, protoSpec{spec})
+	require.NoError(t, err)
+
+	return r
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	const spec = `
+name: foo
+labels:
+  a: b
+  c: d
+items:
+  - name: one
+    value: 1
+  - name: two
+    value: 2
+tags: [x, y]
+`
+
+	for _, tt := range []struct {
+		name     string
+		patch    patch.Patch
+		expected interface{}
+	}{
+		{
+			name: "merge",
+			patch: patch.Patch{
+				Type: patch.MergeJSON,
+				Data: []byte(`{"name": "bar", "labels": {"a": null, "e": "f"}, "items": [{"name": "three", "value": 3}]}`),
+			},
+			expected: map[string]interface{}{
+				"name":   "bar",
+				"labels": map[string]interface{}{"c": "d", "e": "f"},
+				"items": []interface{}{
+					map[string]interface{}{"name": "three", "value": 3},
+				},
+				"tags": []interface{}{"x", "y"},
+			},
+		},
+		{
+			name: "strategic",
+			patch: patch.Patch{
+				Type: patch.StrategicYAML,
+				Data: []byte(`
+labels:
+  $patch: replace
+  e: f
+items:
+  - name: two
+    value: 22
+  - name: one
+    $patch: delete
+  - name: three
+    value: 3
+tags: [z]
+`),
+			},
+			expected: map[string]interface{}{
+				"name":   "foo",
+				"labels": map[string]interface{}{"e": "f"},
+				"items": []interface{}{
+					map[string]interface{}{"name": "two", "value": 22},
+					map[string]interface{}{"name": "three", "value": 3},
+				},
+				"tags": []interface{}{"z"},
+			},
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := newAny(t, spec)
+
+			require.NoError(t, patch.Apply(r, tt.patch))
+
+			assert.Equal(t, tt.expected, r.Value())
+		})
+	}
+}
+
+func TestApplyStrategicNonScalarMergeKey(t *testing.T) {
+	t.Parallel()
+
+	r := newAny(t, `
+items:
+  - name:
+      a: 1
+`)
+
+	require.NoError(t, patch.Apply(r, patch.Patch{
+		Type: patch.StrategicYAML,
+		Data: []byte(`
+items:
+  - name:
+      a: 1
+    x: 2
+`),
+	}))
+
+	// elements can't be matched, so the list is replaced
+	assert.Equal(t, map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": map[string]interface{}{"a": 1}, "x": 2},
+		},
+	}, r.Value())
+}
+
+func TestTypeString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "merge", patch.MergeJSON.String())
+	assert.Equal(t, "strategic", patch.StrategicYAML.String())
+	assert.Equal(t, "Type(5)", patch.Type(5).String())
+}
+
+func TestApplyTyped(t *testing.T) {
+	t.Parallel()
+
+	r := meta.NewNamespace("ns", meta.NamespaceSpec{
+		Description: "foo",
+	})
+
+	require.NoError(t, patch.Apply(r, patch.Patch{
+		Type: patch.MergeJSON,
+		Data: []byte(`{"description": "bar"}`),
+	}))
+
+	assert.Equal(t, meta.NamespaceSpec{Description: "bar"}, r.Spec())
+
+	assert.EqualError(t, patch.Apply(resource.NewTombstone(r.Metadata()), patch.Patch{}), "resource Tombstone(Namespaces.meta.cosi.dev(meta/ns@1)) doesn't support spec unmarshaling")
+}
@@ -0,0 +1,170 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package patch
+
+// MergeKeys is a list of keys used to match list elements in the strategic patch.
+//
+// Lists are merged element by element if all the elements of both lists are
+// mappings containing the same merge key with a scalar value, otherwise the list is replaced.
+var MergeKeys = []string{"id", "name"}
+
+const (
+	directiveKey     = "$patch"
+	directiveDelete  = "delete"
+	directiveReplace = "replace"
+)
+
+// mergeStrategic merges YAML patch into the target.
+//
+// Mappings are merged recursively, null values remove the key.
+// Directive `$patch: replace` replaces the mapping instead of merging it,
+// `$patch: delete` removes the mapping (or list element).
+func mergeStrategic(target, patch interface{}) interface{} {
+	switch patchValue := patch.(type) {
+	case map[string]interface{}:
+		return mergeStrategicMap(target, patchValue)
+	case []interface{}:
+		return mergeStrategicList(target, patchValue)
+	default:
+		return patch
+	}
+}
+
+func mergeStrategicMap(target interface{}, patch map[string]interface{}) interface{} {
+	targetMap, ok := target.(map[string]interface{})
+	if !ok {
+		targetMap = map[string]interface{}{}
+	}
+
+	switch patch[directiveKey] {
+	case directiveDelete:
+		return nil
+	case directiveReplace:
+		targetMap = map[string]interface{}{}
+	}
+
+	for key, value := range patch {
+		if key == directiveKey {
+			continue
+		}
+
+		if value == nil {
+			delete(targetMap, key)
+
+			continue
+		}
+
+		merged := mergeStrategic(targetMap[key], value)
+		if merged == nil {
+			delete(targetMap, key)
+
+			continue
+		}
+
+		targetMap[key] = merged
+	}
+
+	return targetMap
+}
+
+func mergeStrategicList(target interface{}, patch []interface{}) interface{} {
+	targetList, ok := target.([]interface{})
+	if !ok {
+		return stripDirectives(patch)
+	}
+
+	mergeKey := findMergeKey(targetList, patch)
+	if mergeKey == "" {
+		return stripDirectives(patch)
+	}
+
+	result := append([]interface{}(nil), targetList...)
+
+	for _, item := range patch {
+		patchItem := item.(map[string]interface{}) //nolint: errcheck
+
+		idx := -1
+
+		for i := range result {
+			if result[i].(map[string]interface{})[mergeKey] == patchItem[mergeKey] {
+				idx = i
+
+				break
+			}
+		}
+
+		if idx == -1 {
+			if patchItem[directiveKey] != directiveDelete {
+				result = append(result, mergeStrategic(nil, patchItem))
+			}
+
+			continue
+		}
+
+		merged := mergeStrategic(result[idx], patchItem)
+		if merged == nil {
+			result = append(result[:idx], result[idx+1:]...)
+
+			continue
+		}
+
+		result[idx] = merged
+	}
+
+	return result
+}
+
+func findMergeKey(lists ...[]interface{}) string {
+	for _, key := range MergeKeys {
+		found := true
+
+		for _, list := range lists {
+			for _, item := range list {
+				m, ok := item.(map[string]interface{})
+				if !ok {
+					return ""
+				}
+
+				if value, ok := m[key]; !ok || !isScalar(value) {
+					found = false
+				}
+			}
+		}
+
+		if found {
+			return key
+		}
+	}
+
+	return ""
+}
+
+// isScalar checks whether the value can be used to match list elements.
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	default:
+		return true
+	}
+}
+
+func stripDirectives(list []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(list))
+
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			if m[directiveKey] == directiveDelete {
+				continue
+			}
+
+			item = mergeStrategic(nil, m)
+		}
+
+		result = append(result, item)
+	}
+
+	return result
+}
@@ -4,11 +4,15 @@
 
 package resource
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
 // Phase represents state of the resource.
 //
 // Resource might be either Running or TearingDown (waiting for the finalizers to be removed).
+// Additional lifecycle phases might be registered with RegisterPhase.
 type Phase int
 
 // Phase constants.
@@ -22,18 +26,113 @@ const (
 	strPhaseTearingDown = "tearingDown"
 )
 
+// phases keeps registered phases and legal transitions between them.
+var phases = struct {
+	mu          sync.RWMutex
+	names       []string
+	transitions map[Phase]map[Phase]struct{}
+}{
+	names: []string{strPhaseRunning, strPhaseTearingDown},
+	transitions: map[Phase]map[Phase]struct{}{
+		PhaseRunning: {
+			PhaseTearingDown: {},
+		},
+	},
+}
+
 func (ph Phase) String() string {
-	return [...]string{strPhaseRunning, strPhaseTearingDown}[ph]
+	phases.mu.RLock()
+	defer phases.mu.RUnlock()
+
+	if ph < 0 || int(ph) >= len(phases.names) {
+		return fmt.Sprintf("Phase(%d)", int(ph))
+	}
+
+	return phases.names[ph]
 }
 
 // ParsePhase from string representation.
 func ParsePhase(ph string) (Phase, error) {
-	switch ph {
-	case strPhaseRunning:
-		return PhaseRunning, nil
-	case strPhaseTearingDown:
-		return PhaseTearingDown, nil
-	default:
-		return 0, fmt.Errorf("uknown phase: %v", ph)
+	phases.mu.RLock()
+	defer phases.mu.RUnlock()
+
+	for i, name := range phases.names {
+		if name == ph {
+			return Phase(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("uknown phase: %v", ph)
+}
+
+// Phases returns all registered phases.
+func Phases() []Phase {
+	phases.mu.RLock()
+	defer phases.mu.RUnlock()
+
+	result := make([]Phase, len(phases.names))
+
+	for i := range result {
+		result[i] = Phase(i)
+	}
+
+	return result
+}
+
+// RegisterPhase registers an additional lifecycle phase.
+//
+// The new phase has no legal transitions until they are declared with AllowPhaseTransition.
+// RegisterPhase should be called on initialization, it panics if the phase is already registered.
+func RegisterPhase(name string) Phase {
+	phases.mu.Lock()
+	defer phases.mu.Unlock()
+
+	for _, existing := range phases.names {
+		if existing == name {
+			panic(fmt.Sprintf("phase %q is already registered", name))
+		}
 	}
+
+	phases.names = append(phases.names, name)
+
+	return Phase(len(phases.names) - 1)
+}
+
+// AllowPhaseTransition declares the transition from one phase to another as legal.
+//
+// AllowPhaseTransition panics if any of the phases is not registered.
+func AllowPhaseTransition(from, to Phase) {
+	phases.mu.Lock()
+	defer phases.mu.Unlock()
+
+	for _, ph := range []Phase{from, to} {
+		if ph < 0 || int(ph) >= len(phases.names) {
+			panic(fmt.Sprintf("phase %d is not registered", int(ph)))
+		}
+	}
+
+	if phases.transitions[from] == nil {
+		phases.transitions[from] = map[Phase]struct{}{}
+	}
+
+	phases.transitions[from][to] = struct{}{}
+}
+
+// ValidatePhaseTransition checks whether the resource might move from one phase to another.
+//
+// Staying in the same phase is always legal.
+func ValidatePhaseTransition(from, to Phase) error {
+	if from == to {
+		return nil
+	}
+
+	phases.mu.RLock()
+	_, ok := phases.transitions[from][to]
+	phases.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("phase transition from %q to %q is not allowed", from, to)
+	}
+
+	return nil
 }
@@ -0,0 +1,42 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package resource_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+)
+
+func TestPhaseTransitions(t *testing.T) {
+	assert.NoError(t, resource.ValidatePhaseTransition(resource.PhaseRunning, resource.PhaseRunning))
+	assert.NoError(t, resource.ValidatePhaseTransition(resource.PhaseRunning, resource.PhaseTearingDown))
+	assert.EqualError(t, resource.ValidatePhaseTransition(resource.PhaseTearingDown, resource.PhaseRunning), `phase transition from "tearingDown" to "running" is not allowed`)
+
+	suspended := resource.RegisterPhase("suspended")
+
+	assert.Equal(t, "suspended", suspended.String())
+
+	ph, err := resource.ParsePhase("suspended")
+	require.NoError(t, err)
+	assert.Equal(t, suspended, ph)
+
+	assert.Error(t, resource.ValidatePhaseTransition(resource.PhaseRunning, suspended))
+
+	resource.AllowPhaseTransition(resource.PhaseRunning, suspended)
+	resource.AllowPhaseTransition(suspended, resource.PhaseRunning)
+
+	assert.NoError(t, resource.ValidatePhaseTransition(resource.PhaseRunning, suspended))
+	assert.NoError(t, resource.ValidatePhaseTransition(suspended, resource.PhaseRunning))
+	assert.Error(t, resource.ValidatePhaseTransition(suspended, resource.PhaseTearingDown))
+
+	assert.Panics(t, func() { resource.RegisterPhase("running") })
+	assert.Panics(t, func() { resource.AllowPhaseTransition(suspended, resource.Phase(100)) })
+
+	assert.Equal(t, "Phase(100)", resource.Phase(100).String())
+}
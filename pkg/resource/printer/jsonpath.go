@@ -0,0 +1,175 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package printer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathStep is a single step of the JSONPath expression.
+type pathStep struct {
+	key      string
+	index    int
+	wildcard bool
+	isIndex  bool
+}
+
+// JSONPath is a compiled JSONPath expression.
+//
+// Supported subset: field access (`.field`, `['field']`), array index (`[0]`, `[-1]`)
+// and wildcards (`[*]`, `.*`). Expression might be wrapped in braces: `{.spec.field}`.
+type JSONPath struct {
+	expr  string
+	steps []pathStep
+}
+
+// ParseJSONPath compiles JSONPath expression.
+func ParseJSONPath(expr string) (*JSONPath, error) {
+	path := &JSONPath{
+		expr: expr,
+	}
+
+	s := strings.TrimSpace(expr)
+
+	if strings.HasPrefix(s, "{") {
+		if !strings.HasSuffix(s, "}") {
+			return nil, fmt.Errorf("jsonpath %q: unbalanced braces", expr)
+		}
+
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+
+	s = strings.TrimPrefix(s, "$")
+
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+
+			end := strings.IndexAny(s, ".[")
+			if end == -1 {
+				end = len(s)
+			}
+
+			name := s[:end]
+			s = s[end:]
+
+			switch name {
+			case "":
+				if len(s) > 0 {
+					return nil, fmt.Errorf("jsonpath %q: empty field name", expr)
+				}
+			case "*":
+				path.steps = append(path.steps, pathStep{wildcard: true})
+			default:
+				path.steps = append(path.steps, pathStep{key: name})
+			}
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("jsonpath %q: unterminated subscript", expr)
+			}
+
+			step, err := parseSubscript(s[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath %q: %w", expr, err)
+			}
+
+			path.steps = append(path.steps, step)
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("jsonpath %q: unexpected character %q", expr, s[0])
+		}
+	}
+
+	return path, nil
+}
+
+func parseSubscript(subscript string) (pathStep, error) {
+	subscript = strings.TrimSpace(subscript)
+
+	switch {
+	case subscript == "*":
+		return pathStep{wildcard: true}, nil
+	case len(subscript) >= 2 && (subscript[0] == '\'' || subscript[0] == '"') && subscript[len(subscript)-1] == subscript[0]:
+		return pathStep{key: subscript[1 : len(subscript)-1]}, nil
+	default:
+		idx, err := strconv.Atoi(subscript)
+		if err != nil {
+			return pathStep{}, fmt.Errorf("invalid subscript %q", subscript)
+		}
+
+		return pathStep{index: idx, isIndex: true}, nil
+	}
+}
+
+// String returns the source expression.
+func (path *JSONPath) String() string {
+	return path.expr
+}
+
+// Evaluate the expression against the value decoded from YAML or JSON.
+//
+// Evaluate returns all the matched values, missing fields are skipped.
+func (path *JSONPath) Evaluate(value interface{}) []interface{} {
+	current := []interface{}{value}
+
+	for _, step := range path.steps {
+		var next []interface{}
+
+		for _, v := range current {
+			next = append(next, step.apply(v)...)
+		}
+
+		current = next
+	}
+
+	return current
+}
+
+func (step pathStep) apply(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if step.wildcard {
+			keys := sortedKeys(v)
+			result := make([]interface{}, 0, len(keys))
+
+			for _, key := range keys {
+				result = append(result, v[key])
+			}
+
+			return result
+		}
+
+		if step.isIndex {
+			return nil
+		}
+
+		if item, ok := v[step.key]; ok {
+			return []interface{}{item}
+		}
+	case []interface{}:
+		if step.wildcard {
+			return v
+		}
+
+		if !step.isIndex {
+			return nil
+		}
+
+		idx := step.index
+		if idx < 0 {
+			idx += len(v)
+		}
+
+		if idx >= 0 && idx < len(v) {
+			return []interface{}{v[idx]}
+		}
+	}
+
+	return nil
+}
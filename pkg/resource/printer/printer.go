@@ -0,0 +1,152 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package printer renders resources as tables driven by ResourceDefinition print columns.
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+)
+
+var defaultColumns = []string{"NAMESPACE", "TYPE", "ID", "VERSION", "PHASE"}
+
+type column struct {
+	name string
+	path *JSONPath
+}
+
+// TablePrinter renders resources of a single type as an aligned table.
+type TablePrinter struct {
+	definition meta.ResourceDefinitionSpec
+	columns    []column
+}
+
+// NewTablePrinter creates a TablePrinter for resources described by the definition.
+//
+// Default columns (namespace, type, ID, version, phase) are followed by the print columns of the definition.
+// JSONPath expressions of the print columns are evaluated against the spec converted to its YAML form.
+func NewTablePrinter(definition meta.ResourceDefinitionSpec) (*TablePrinter, error) {
+	printer := &TablePrinter{
+		definition: definition,
+		columns:    make([]column, 0, len(definition.PrintColumns)),
+	}
+
+	for _, printColumn := range definition.PrintColumns {
+		path, err := ParseJSONPath(printColumn.JSONPath)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing print column %q: %w", printColumn.Name, err)
+		}
+
+		printer.columns = append(printer.columns, column{
+			name: printColumn.Name,
+			path: path,
+		})
+	}
+
+	return printer, nil
+}
+
+// Print the list of resources.
+func (printer *TablePrinter) Print(w io.Writer, list resource.List) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+
+	header := append([]string(nil), defaultColumns...)
+
+	for _, col := range printer.columns {
+		header = append(header, strings.ToUpper(col.name))
+	}
+
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, r := range list.Items {
+		row, err := printer.row(r)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func (printer *TablePrinter) row(r resource.Resource) ([]string, error) {
+	md := r.Metadata()
+
+	typ := printer.definition.DisplayType
+	if typ == "" {
+		typ = md.Type()
+	}
+
+	row := []string{md.Namespace(), typ, md.ID(), md.Version().String(), md.Phase().String()}
+
+	if len(printer.columns) == 0 {
+		return row, nil
+	}
+
+	var spec interface{}
+
+	if !resource.IsTombstone(r) {
+		specYAML, err := yaml.Marshal(r.Spec())
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling spec of %s: %w", r, err)
+		}
+
+		if err = yaml.Unmarshal(specYAML, &spec); err != nil {
+			return nil, fmt.Errorf("error decoding spec of %s: %w", r, err)
+		}
+	}
+
+	for _, col := range printer.columns {
+		values := col.path.Evaluate(spec)
+
+		formatted := make([]string, 0, len(values))
+
+		for _, value := range values {
+			formatted = append(formatted, formatValue(value))
+		}
+
+		row = append(row, strings.Join(formatted, ","))
+	}
+
+	return row, nil
+}
+
+func formatValue(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return ""
+	case map[string]interface{}, []interface{}:
+		out, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+
+		return string(out)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,117 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package printer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/manifest"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/resource/printer"
+)
+
+func TestJSONPath(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]interface{}{
+		"name": "foo",
+		"items": []interface{}{
+			map[string]interface{}{"id": 1, "tags": []interface{}{"a", "b"}},
+			map[string]interface{}{"id": 2, "tags": []interface{}{"c"}},
+		},
+		"labels": map[string]interface{}{"x.y": "z", "a": "b"},
+	}
+
+	for _, tt := range []struct {
+		expr     string
+		expected []interface{}
+	}{
+		{"{.name}", []interface{}{"foo"}},
+		{".name", []interface{}{"foo"}},
+		{"$.missing", nil},
+		{"{.items[0].id}", []interface{}{1}},
+		{"{.items[-1].id}", []interface{}{2}},
+		{"{.items[5].id}", nil},
+		{"{.items[*].id}", []interface{}{1, 2}},
+		{"{.items[*].tags[*]}", []interface{}{"a", "b", "c"}},
+		{"{.labels['x.y']}", []interface{}{"z"}},
+		{"{.labels.*}", []interface{}{"b", "z"}},
+		{"{.name[0]}", nil},
+		{"{}", []interface{}{value}},
+	} {
+		path, err := printer.ParseJSONPath(tt.expr)
+		require.NoError(t, err, tt.expr)
+
+		assert.Equal(t, tt.expected, path.Evaluate(value), tt.expr)
+	}
+
+	for _, expr := range []string{"{.name", ".items[0", ".items[x]", "name", ".a..b"} {
+		_, err := printer.ParseJSONPath(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestTablePrinter(t *testing.T) {
+	t.Parallel()
+
+	resources, err := manifest.ReadAll(strings.NewReader(`
+metadata:
+    namespace: default
+    type: Disks.cosi.dev
+    id: sda
+    version: 1
+spec:
+    size: 100
+    partitions:
+      - name: boot
+      - name: root
+---
+metadata:
+    namespace: default
+    type: Disks.cosi.dev
+    id: nvme0n1
+    version: 12
+    phase: tearingDown
+spec:
+    size: 2000
+    model: {vendor: acme}
+`))
+	require.NoError(t, err)
+
+	definition := meta.ResourceDefinitionSpec{
+		Type: "Disks.cosi.dev",
+		PrintColumns: []meta.PrintColumn{
+			{Name: "Size", JSONPath: "{.size}"},
+			{Name: "Partitions", JSONPath: "{.partitions[*].name}"},
+			{Name: "Model", JSONPath: "{.model}"},
+		},
+	}
+
+	require.NoError(t, definition.Fill())
+
+	p, err := printer.NewTablePrinter(definition)
+	require.NoError(t, err)
+
+	var out strings.Builder
+
+	require.NoError(t, p.Print(&out, resource.List{Items: resources}))
+
+	assert.Equal(t, `NAMESPACE   TYPE   ID        VERSION   PHASE         SIZE   PARTITIONS   MODEL
+default     Disk   sda       1         running       100    boot,root    
+default     Disk   nvme0n1   12        tearingDown   2000                {"vendor":"acme"}
+`, out.String())
+
+	_, err = printer.NewTablePrinter(meta.ResourceDefinitionSpec{
+		PrintColumns: []meta.PrintColumn{
+			{Name: "Bad", JSONPath: "{.a"},
+		},
+	})
+	assert.EqualError(t, err, `error parsing print column "Bad": jsonpath "{.a": unbalanced braces`)
+}
@@ -0,0 +1,121 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package protobuf converts resources and events to and from the protobuf representation.
+package protobuf
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/api/v1alpha1"
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/state"
+)
+
+// MetadataToProto converts resource metadata to protobuf representation.
+func MetadataToProto(md *resource.Metadata) *v1alpha1.Metadata {
+	return &v1alpha1.Metadata{
+		Namespace:  md.Namespace(),
+		Type:       md.Type(),
+		Id:         md.ID(),
+		Version:    md.Version().String(),
+		Phase:      md.Phase().String(),
+		Finalizers: append([]string(nil), *md.Finalizers()...),
+	}
+}
+
+// MetadataFromProto converts protobuf representation to resource metadata.
+func MetadataFromProto(protoMd *v1alpha1.Metadata) (resource.Metadata, error) {
+	return resource.NewMetadataFromProto(protoMd)
+}
+
+// ResourceToProto converts resource to protobuf representation.
+//
+// Spec is encoded as YAML, tombstones are encoded without spec.
+func ResourceToProto(r resource.Resource) (*v1alpha1.Resource, error) {
+	protoResource := &v1alpha1.Resource{
+		Metadata: MetadataToProto(r.Metadata()),
+	}
+
+	if resource.IsTombstone(r) {
+		return protoResource, nil
+	}
+
+	specYAML, err := yaml.Marshal(r.Spec())
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling spec of %s: %w", r, err)
+	}
+
+	protoResource.Spec = &v1alpha1.Spec{
+		Yaml: specYAML,
+	}
+
+	return protoResource, nil
+}
+
+// ResourceFromProto converts protobuf representation to resource.
+//
+// Resources are decoded as resource.Any, resources without spec are decoded as resource.Tombstone.
+func ResourceFromProto(protoResource *v1alpha1.Resource) (resource.Resource, error) {
+	if protoResource.GetSpec() == nil {
+		md, err := MetadataFromProto(protoResource.GetMetadata())
+		if err != nil {
+			return nil, err
+		}
+
+		return resource.NewTombstone(md), nil
+	}
+
+	return resource.NewAnyFromProto(protoResource.GetMetadata(), protoResource.GetSpec())
+}
+
+// EventToProto converts state event to protobuf representation.
+func EventToProto(event state.Event) (*v1alpha1.Event, error) {
+	var eventType v1alpha1.EventType
+
+	switch event.Type {
+	case state.Created:
+		eventType = v1alpha1.EventType_CREATED
+	case state.Updated:
+		eventType = v1alpha1.EventType_UPDATED
+	case state.Destroyed:
+		eventType = v1alpha1.EventType_DESTROYED
+	default:
+		return nil, fmt.Errorf("unsupported event type %v", event.Type)
+	}
+
+	protoResource, err := ResourceToProto(event.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1alpha1.Event{
+		EventType: eventType,
+		Resource:  protoResource,
+	}, nil
+}
+
+// EventFromProto converts protobuf representation to state event.
+func EventFromProto(protoEvent *v1alpha1.Event) (state.Event, error) {
+	var event state.Event
+
+	switch protoEvent.GetEventType() {
+	case v1alpha1.EventType_CREATED:
+		event.Type = state.Created
+	case v1alpha1.EventType_UPDATED:
+		event.Type = state.Updated
+	case v1alpha1.EventType_DESTROYED:
+		event.Type = state.Destroyed
+	default:
+		return event, fmt.Errorf("unsupported event type %v", protoEvent.GetEventType())
+	}
+
+	var err error
+
+	event.Resource, err = ResourceFromProto(protoEvent.GetResource())
+
+	return event, err
+}
@@ -0,0 +1,83 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package protobuf_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/talos-systems/os-runtime/api/v1alpha1"
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/resource/protobuf"
+	"github.com/talos-systems/os-runtime/pkg/state"
+)
+
+func TestResourceRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	ns := meta.NewNamespace("user", meta.NamespaceSpec{
+		Description: "user namespace",
+	})
+	ns.Metadata().Finalizers().Add("A")
+	ns.Metadata().SetPhase(resource.PhaseTearingDown)
+
+	protoEvent, err := protobuf.EventToProto(state.Event{
+		Type:     state.Updated,
+		Resource: ns,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, proto.Equal(&v1alpha1.Event{
+		EventType: v1alpha1.EventType_UPDATED,
+		Resource: &v1alpha1.Resource{
+			Metadata: &v1alpha1.Metadata{
+				Namespace:  meta.NamespaceName,
+				Type:       meta.NamespaceType,
+				Id:         "user",
+				Version:    "1",
+				Phase:      "tearingDown",
+				Finalizers: []string{"A"},
+			},
+			Spec: &v1alpha1.Spec{
+				Yaml: []byte("description: user namespace\n"),
+			},
+		},
+	}, protoEvent))
+
+	b, err := proto.Marshal(protoEvent)
+	require.NoError(t, err)
+
+	var decoded v1alpha1.Event
+
+	require.NoError(t, proto.Unmarshal(b, &decoded))
+
+	event, err := protobuf.EventFromProto(&decoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, state.Updated, event.Type)
+	assert.True(t, ns.Metadata().Equal(*event.Resource.Metadata()))
+	assert.Equal(t, map[string]interface{}{"description": "user namespace"}, event.Resource.(*resource.Any).Value())
+}
+
+func TestTombstoneRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	tombstone := resource.NewTombstone(resource.NewMetadata("default", "type", "a", resource.VersionUndefined))
+
+	protoResource, err := protobuf.ResourceToProto(tombstone)
+	require.NoError(t, err)
+
+	assert.Nil(t, protoResource.Spec)
+
+	r, err := protobuf.ResourceFromProto(protoResource)
+	require.NoError(t, err)
+
+	assert.True(t, resource.IsTombstone(r))
+	assert.Equal(t, tombstone.String(), r.String())
+}
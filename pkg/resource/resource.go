@@ -7,7 +7,6 @@ package resource
 
 import (
 	"fmt"
-	"reflect"
 )
 
 type (
@@ -42,13 +41,21 @@ type Resource interface {
 	DeepCopy() Resource
 }
 
+// SpecUnmarshaler is implemented by resources which can replace their spec from YAML representation.
+type SpecUnmarshaler interface {
+	UnmarshalSpecYAML([]byte) error
+}
+
 // Equal tests two resources for equality.
+//
+// Specs are compared using SpecEqualer or SpecHasher if the resource implements them,
+// falling back to reflect.DeepEqual.
 func Equal(r1, r2 Resource) bool {
 	if !r1.Metadata().Equal(*r2.Metadata()) {
 		return false
 	}
 
-	return reflect.DeepEqual(r1.Spec(), r2.Spec())
+	return specEqual(r1, r2)
 }
 
 // MarshalYAML marshals resource to YAML definition.
@@ -0,0 +1,191 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package schema builds JSON Schema/OpenAPI descriptions of resources.
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+)
+
+// Schema is a subset of JSON Schema which is compatible with OpenAPI v3 schema objects.
+type Schema struct {
+	Ref         string `json:"$ref,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Format      string `json:"format,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+
+	Enum  []interface{} `json:"enum,omitempty"`
+	AnyOf []*Schema     `json:"anyOf,omitempty"`
+	Const interface{}   `json:"const,omitempty"`
+}
+
+// Metadata returns the schema of the resource metadata as produced by resource.MarshalYAML.
+func Metadata() *Schema {
+	phases := resource.Phases()
+	phaseNames := make([]interface{}, 0, len(phases))
+
+	for _, phase := range phases {
+		phaseNames = append(phaseNames, phase.String())
+	}
+
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"namespace": {Type: "string", Description: "Namespace of the resource, defaults to the default namespace of the resource type."},
+			"type":      {Type: "string", Description: "Resource type."},
+			"id":        {Type: "string", Description: "Resource ID."},
+			"version": {
+				Description: "Resource version.",
+				AnyOf:       []*Schema{{Type: "integer"}, {Type: "string"}},
+			},
+			"phase": {Type: "string", Description: "Resource lifecycle phase.", Enum: phaseNames},
+			"finalizers": {
+				Type:        "array",
+				Description: "Finalizers block resource destruction.",
+				Items:       &Schema{Type: "string"},
+			},
+		},
+		Required: []string{"type", "id"},
+	}
+}
+
+// Resource returns the schema of the resource as produced by resource.MarshalYAML.
+//
+// If spec is nil, spec is described as a free-form object.
+func Resource(typ resource.Type, spec *Schema) *Schema {
+	md := Metadata()
+	md.Properties["type"].Const = typ
+
+	if spec == nil {
+		spec = &Schema{}
+	}
+
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"metadata": md,
+			"spec":     spec,
+		},
+		Required: []string{"metadata"},
+	}
+}
+
+var (
+	yamlMarshalerType = reflect.TypeOf((*yaml.Marshaler)(nil)).Elem()
+	durationType      = reflect.TypeOf(time.Duration(0))
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// ForType builds the schema of the YAML representation of the Go type.
+//
+// Types with custom YAML marshaling are described as any value.
+func ForType(typ reflect.Type) *Schema {
+	return forType(typ, map[reflect.Type]struct{}{})
+}
+
+//nolint: gocyclo, cyclop
+func forType(typ reflect.Type, visiting map[reflect.Type]struct{}) *Schema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ {
+	case durationType:
+		return &Schema{Type: "string", Format: "duration"}
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	if typ.Implements(yamlMarshalerType) || reflect.PtrTo(typ).Implements(yamlMarshalerType) {
+		return &Schema{}
+	}
+
+	switch typ.Kind() { //nolint: exhaustive
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: forType(typ.Elem(), visiting)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: forType(typ.Elem(), visiting)}
+	case reflect.Struct:
+		if _, ok := visiting[typ]; ok {
+			// recursive type
+			return &Schema{Type: "object"}
+		}
+
+		visiting[typ] = struct{}{}
+		defer delete(visiting, typ)
+
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+		addFields(schema, typ, visiting)
+
+		return schema
+	default:
+		return &Schema{}
+	}
+}
+
+func addFields(schema *Schema, typ reflect.Type, visiting map[reflect.Type]struct{}) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if strings.Contains(opts, "inline") {
+			inlined := field.Type
+			for inlined.Kind() == reflect.Ptr {
+				inlined = inlined.Elem()
+			}
+
+			if inlined.Kind() == reflect.Struct {
+				addFields(schema, inlined, visiting)
+			}
+
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		schema.Properties[name] = forType(field.Type, visiting)
+	}
+}
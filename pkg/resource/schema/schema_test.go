@@ -0,0 +1,81 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package schema_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/schema"
+)
+
+type common struct {
+	Labels map[string]string `yaml:"labels"`
+}
+
+type node struct {
+	Name     string  `yaml:"name"`
+	Children []*node `yaml:"children"`
+}
+
+type spec struct {
+	common `yaml:",inline"`
+
+	Name     string        `yaml:"name"`
+	Count    *int          `yaml:"count,omitempty"`
+	Ratio    float64       `yaml:"ratio"`
+	Enabled  bool          `yaml:"enabled"`
+	Data     []byte        `yaml:"data"`
+	Timeout  time.Duration `yaml:"timeout"`
+	Tree     node          `yaml:"tree"`
+	Version  resource.Version
+	Ignored  string `yaml:"-"`
+	internal string
+}
+
+func TestForType(t *testing.T) {
+	s := schema.ForType(reflect.TypeOf(spec{}))
+
+	assert.Equal(t, &schema.Schema{
+		Type: "object",
+		Properties: map[string]*schema.Schema{
+			"labels":  {Type: "object", AdditionalProperties: &schema.Schema{Type: "string"}},
+			"name":    {Type: "string"},
+			"count":   {Type: "integer"},
+			"ratio":   {Type: "number"},
+			"enabled": {Type: "boolean"},
+			"data":    {Type: "string", Format: "byte"},
+			"timeout": {Type: "string", Format: "duration"},
+			"tree": {
+				Type: "object",
+				Properties: map[string]*schema.Schema{
+					"name": {Type: "string"},
+					"children": {
+						Type:  "array",
+						Items: &schema.Schema{Type: "object"},
+					},
+				},
+			},
+			"version": {Type: "object", Properties: map[string]*schema.Schema{}},
+		},
+	}, s)
+}
+
+func TestResource(t *testing.T) {
+	s := schema.Resource("Tests.cosi.dev", nil)
+
+	assert.Equal(t, []string{"metadata"}, s.Required)
+	assert.Equal(t, &schema.Schema{}, s.Properties["spec"])
+
+	md := s.Properties["metadata"]
+	assert.Equal(t, "Tests.cosi.dev", md.Properties["type"].Const)
+	assert.Equal(t, []string{"type", "id"}, md.Required)
+	assert.Contains(t, md.Properties["phase"].Enum, "running")
+	assert.Contains(t, md.Properties["phase"].Enum, "tearingDown")
+}
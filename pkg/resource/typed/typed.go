@@ -0,0 +1,97 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package typed provides a generic resource implementation parameterized on the spec type.
+package typed
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta/spec"
+)
+
+// DeepCopyable is implemented by specs which can deep copy themselves.
+type DeepCopyable[T any] interface {
+	DeepCopy() T
+}
+
+// Extension provides static information about the resource type.
+//
+// Extension is usually an empty struct type.
+type Extension interface {
+	ResourceDefinition() spec.ResourceDefinitionSpec
+}
+
+// Resource implements resource.Resource for the spec of type T.
+//
+// Resource types are declared as type aliases:
+//
+//	type Namespace = typed.Resource[NamespaceSpec, NamespaceExtension]
+type Resource[T DeepCopyable[T], E Extension] struct {
+	md   resource.Metadata
+	spec T
+}
+
+// NewResource initializes a Resource with the given metadata and spec.
+func NewResource[T DeepCopyable[T], E Extension](md resource.Metadata, spec T) *Resource[T, E] {
+	return &Resource[T, E]{
+		md:   md,
+		spec: spec,
+	}
+}
+
+// Metadata implements resource.Resource.
+func (t *Resource[T, E]) Metadata() *resource.Metadata {
+	return &t.md
+}
+
+// Spec implements resource.Resource.
+func (t *Resource[T, E]) Spec() interface{} {
+	return t.spec
+}
+
+// TypedSpec returns a pointer to the spec which can be used to update it.
+func (t *Resource[T, E]) TypedSpec() *T {
+	return &t.spec
+}
+
+func (t *Resource[T, E]) String() string {
+	return fmt.Sprintf("%s(%q)", t.md.Type(), t.md.ID())
+}
+
+// DeepCopy implements resource.Resource.
+func (t *Resource[T, E]) DeepCopy() resource.Resource {
+	return &Resource[T, E]{
+		md:   t.md.Copy(),
+		spec: t.spec.DeepCopy(),
+	}
+}
+
+// ResourceDefinition implements meta.ResourceDefinitionProvider interface.
+func (t *Resource[T, E]) ResourceDefinition() spec.ResourceDefinitionSpec {
+	var extension E
+
+	return extension.ResourceDefinition()
+}
+
+// MarshalYAML implements yaml.Marshaler interface.
+func (t *Resource[T, E]) MarshalYAML() (interface{}, error) {
+	return resource.MarshalYAML(t)
+}
+
+// UnmarshalSpecYAML implements resource.SpecUnmarshaler.
+func (t *Resource[T, E]) UnmarshalSpecYAML(data []byte) error {
+	var spec T
+
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+
+	t.spec = spec
+
+	return nil
+}
@@ -0,0 +1,93 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package typed_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/resource/typed"
+)
+
+type testSpec struct {
+	Values []string `yaml:"values"`
+}
+
+func (spec testSpec) DeepCopy() testSpec {
+	return testSpec{
+		Values: append([]string(nil), spec.Values...),
+	}
+}
+
+type testExtension struct{}
+
+func (testExtension) ResourceDefinition() meta.ResourceDefinitionSpec {
+	return meta.ResourceDefinitionSpec{
+		Type:             "Tests.os.dev",
+		DefaultNamespace: "default",
+	}
+}
+
+type testResource = typed.Resource[testSpec, testExtension]
+
+func TestInterfaces(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.Resource)(nil), new(testResource))
+	assert.Implements(t, (*resource.SpecUnmarshaler)(nil), new(testResource))
+	assert.Implements(t, (*meta.ResourceDefinitionProvider)(nil), new(testResource))
+}
+
+func TestResource(t *testing.T) {
+	t.Parallel()
+
+	md := resource.NewMetadata("default", "Tests.os.dev", "a", resource.VersionUndefined)
+	md.BumpVersion()
+
+	r := typed.NewResource[testSpec, testExtension](md, testSpec{Values: []string{"a", "b"}})
+
+	assert.Equal(t, `Tests.os.dev("a")`, r.String())
+	assert.Equal(t, testSpec{Values: []string{"a", "b"}}, r.Spec())
+	assert.Equal(t, resource.Type("Tests.os.dev"), r.ResourceDefinition().Type)
+
+	rCopy := r.DeepCopy().(*testResource) //nolint: errcheck
+	rCopy.TypedSpec().Values[0] = "c"
+	rCopy.Metadata().BumpVersion()
+
+	assert.Equal(t, []string{"a", "b"}, r.TypedSpec().Values)
+	assert.Equal(t, "1", r.Metadata().Version().String())
+	assert.False(t, resource.Equal(r, rCopy))
+
+	out, err := yaml.Marshal(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, `metadata:
+    namespace: default
+    type: Tests.os.dev
+    id: a
+    version: 1
+    phase: running
+spec:
+    values:
+      - a
+      - b
+`, string(out))
+
+	require.NoError(t, r.UnmarshalSpecYAML([]byte("values: [d]")))
+	assert.Equal(t, []string{"d"}, r.TypedSpec().Values)
+
+	// finalizers are not shared between the copies
+	r.Metadata().Finalizers().Add("A")
+
+	rCopy = r.DeepCopy().(*testResource) //nolint: errcheck
+	(*rCopy.Metadata().Finalizers())[0] = "B"
+
+	assert.Equal(t, resource.Finalizers{"A"}, *r.Metadata().Finalizers())
+}
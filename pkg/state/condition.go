@@ -24,7 +24,13 @@ type WatchForCondition struct {
 }
 
 // Matches checks whether event matches a condition.
+//
+// Errored events never match, the error of the event is returned instead.
 func (condition *WatchForCondition) Matches(event Event) (bool, error) {
+	if event.Type == Errored {
+		return false, event.Error
+	}
+
 	if condition.EventTypes != nil {
 		matched := false
 
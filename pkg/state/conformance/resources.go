@@ -5,9 +5,9 @@
 package conformance
 
 import (
-	"fmt"
-
 	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/resource/typed"
 )
 
 // PathResourceType is the type of PathResource.
@@ -16,37 +16,30 @@ const PathResourceType = resource.Type("os/path")
 // PathResource represents a path in the filesystem.
 //
 // Resource ID is the path.
-type PathResource struct {
-	md resource.Metadata
-}
+type PathResource = typed.Resource[PathSpec, PathExtension]
 
-// NewPathResource creates new PathResource.
-func NewPathResource(ns resource.Namespace, path string) *PathResource {
-	r := &PathResource{
-		md: resource.NewMetadata(ns, PathResourceType, path, resource.VersionUndefined),
-	}
-	r.md.BumpVersion()
+// PathSpec is the (empty) spec of PathResource.
+type PathSpec struct{}
 
-	return r
+// DeepCopy generates a deep copy of PathSpec.
+func (spec PathSpec) DeepCopy() PathSpec {
+	return spec
 }
 
-// Metadata implements resource.Resource.
-func (path *PathResource) Metadata() *resource.Metadata {
-	return &path.md
-}
+// NewPathResource creates new PathResource.
+func NewPathResource(ns resource.Namespace, path string) *PathResource {
+	md := resource.NewMetadata(ns, PathResourceType, path, resource.VersionUndefined)
+	md.BumpVersion()
 
-// Spec implements resource.Resource.
-func (path *PathResource) Spec() interface{} {
-	return nil
+	return typed.NewResource[PathSpec, PathExtension](md, PathSpec{})
 }
 
-func (path *PathResource) String() string {
-	return fmt.Sprintf("PathResource(%q)", path.md.ID())
-}
+// PathExtension provides auxiliary methods for PathResource.
+type PathExtension struct{}
 
-// DeepCopy implements resource.Resource.
-func (path *PathResource) DeepCopy() resource.Resource {
-	return &PathResource{
-		md: path.md,
+// ResourceDefinition implements typed.Extension interface.
+func (PathExtension) ResourceDefinition() meta.ResourceDefinitionSpec {
+	return meta.ResourceDefinitionSpec{
+		Type: PathResourceType,
 	}
 }
@@ -15,6 +15,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/typed"
 	"github.com/talos-systems/os-runtime/pkg/state"
 )
 
@@ -413,3 +414,162 @@ func (suite *StateSuite) TestUpdate() {
 
 	suite.Assert().NoError(suite.State.Update(ctx, curVersion, path1))
 }
+
+// TestApply verifies create-or-update flow.
+func (suite *StateSuite) TestApply() {
+	ns := suite.getNamespace()
+	path1 := NewPathResource(ns, "tmp/apply")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stored, err := suite.State.Apply(ctx, path1)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(path1.String(), stored.String())
+	suite.Assert().True(stored.Metadata().Version().Equal(path1.Metadata().Version()))
+
+	stored, err = suite.State.Apply(ctx, path1)
+	suite.Require().NoError(err)
+	suite.Assert().True(stored.Metadata().Version().Equal(path1.Metadata().Version()))
+
+	suite.Require().NoError(suite.State.AddFinalizer(ctx, path1.Metadata(), "A"))
+
+	err = suite.State.Create(ctx, path1)
+	suite.Assert().Error(err)
+	suite.Assert().True(state.IsConflictError(err))
+
+	_, err = suite.State.Apply(ctx, path1, state.WithApplyOnlyIfUnchanged())
+	suite.Assert().Error(err)
+	suite.Assert().True(state.IsConflictError(err))
+
+	stored, err = suite.State.Apply(ctx, path1)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(resource.Finalizers{"A"}, *stored.Metadata().Finalizers())
+
+	stored, err = suite.State.Apply(ctx, stored, state.WithApplyUpdater(func(r resource.Resource) error {
+		r.Metadata().SetPhase(resource.PhaseTearingDown)
+
+		return nil
+	}), state.WithApplyOnlyIfUnchanged())
+	suite.Require().NoError(err)
+	suite.Assert().Equal(resource.PhaseTearingDown, stored.Metadata().Phase())
+	suite.Assert().Equal(resource.Finalizers{"A"}, *stored.Metadata().Finalizers())
+
+	r, err := suite.State.Get(ctx, path1.Metadata())
+	suite.Require().NoError(err)
+	suite.Assert().True(resource.Equal(stored, r))
+
+	stored, err = suite.State.Apply(ctx, path1, state.WithApplyForce())
+	suite.Require().NoError(err)
+	suite.Assert().Equal(resource.PhaseRunning, stored.Metadata().Phase())
+	suite.Assert().True(stored.Metadata().Finalizers().Empty())
+
+	suite.Assert().NoError(suite.State.Destroy(ctx, path1.Metadata()))
+
+	// resource is created with the version as passed
+	path2 := typed.NewResource[PathSpec, PathExtension](resource.NewMetadata(ns, PathResourceType, "tmp/apply-undefined", resource.VersionUndefined), PathSpec{})
+
+	stored, err = suite.State.Apply(ctx, path2)
+	suite.Require().NoError(err)
+	suite.Assert().True(stored.Metadata().Version().Equal(resource.VersionUndefined))
+
+	r, err = suite.State.Get(ctx, path2.Metadata())
+	suite.Require().NoError(err)
+	suite.Assert().True(r.Metadata().Version().Equal(resource.VersionUndefined))
+
+	suite.Assert().NoError(suite.State.Destroy(ctx, path2.Metadata()))
+}
+
+// TestPhaseTransitions verifies that illegal phase transitions are rejected.
+func (suite *StateSuite) TestPhaseTransitions() {
+	ns := suite.getNamespace()
+	path1 := NewPathResource(ns, "tmp/phase")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	suite.Require().NoError(suite.State.Create(ctx, path1))
+
+	ready, err := suite.State.Teardown(ctx, path1.Metadata())
+	suite.Require().NoError(err)
+	suite.Assert().True(ready)
+
+	r, err := suite.State.Get(ctx, path1.Metadata())
+	suite.Require().NoError(err)
+
+	curVersion := r.Metadata().Version()
+
+	r.Metadata().SetPhase(resource.PhaseRunning)
+	r.Metadata().BumpVersion()
+
+	err = suite.State.Update(ctx, curVersion, r)
+	suite.Assert().Error(err)
+	suite.Assert().True(state.IsPhaseTransitionError(err))
+
+	_, err = suite.State.UpdateWithConflicts(ctx, path1.Metadata(), func(r resource.Resource) error {
+		r.Metadata().SetPhase(resource.PhaseRunning)
+
+		return nil
+	})
+	suite.Assert().True(state.IsPhaseTransitionError(err))
+
+	suite.Require().NoError(suite.State.Update(ctx, curVersion, r, state.WithForcePhaseTransition()))
+
+	r, err = suite.State.Get(ctx, path1.Metadata())
+	suite.Require().NoError(err)
+	suite.Assert().Equal(resource.PhaseRunning, r.Metadata().Phase())
+
+	suite.Assert().NoError(suite.State.Destroy(ctx, path1.Metadata()))
+}
+
+// TestFinalizerOwnership verifies that owned finalizers can be changed only by the owner.
+func (suite *StateSuite) TestFinalizerOwnership() {
+	ns := suite.getNamespace()
+	path1 := NewPathResource(ns, "tmp/owned")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fin, err := resource.OwnedFinalizer("alice", "dir")
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.State.Create(ctx, path1))
+
+	err = suite.State.AddFinalizer(state.WithOwner(ctx, "bob"), path1.Metadata(), fin)
+	suite.Assert().True(state.IsPermissionDeniedError(err))
+
+	// owner which can't be recorded in the finalizer can't change finalizers
+	err = suite.State.AddFinalizer(state.WithOwner(ctx, "alice:x"), path1.Metadata(), "B")
+	suite.Assert().True(state.IsPermissionDeniedError(err))
+
+	suite.Require().NoError(suite.State.AddFinalizer(state.WithOwner(ctx, "alice"), path1.Metadata(), fin, "B", "alice:legacy"))
+
+	// plain finalizers can be changed by anyone, including the ones which look like "owner:name"
+	suite.Require().NoError(suite.State.RemoveFinalizer(ctx, path1.Metadata(), "B"))
+	suite.Require().NoError(suite.State.RemoveFinalizer(state.WithOwner(ctx, "bob"), path1.Metadata(), "alice:legacy"))
+
+	err = suite.State.RemoveFinalizer(state.WithOwner(ctx, "bob"), path1.Metadata(), fin)
+	suite.Assert().True(state.IsPermissionDeniedError(err))
+
+	err = suite.State.RemoveFinalizer(ctx, path1.Metadata(), fin)
+	suite.Assert().True(state.IsPermissionDeniedError(err))
+
+	r, err := suite.State.Get(ctx, path1.Metadata())
+	suite.Require().NoError(err)
+	suite.Assert().Equal(resource.Finalizers{fin}, *r.Metadata().Finalizers())
+
+	// changes which keep finalizers intact are allowed
+	_, err = suite.State.Teardown(state.WithOwner(ctx, "bob"), path1.Metadata())
+	suite.Require().NoError(err)
+
+	r, err = suite.State.Get(ctx, path1.Metadata())
+	suite.Require().NoError(err)
+
+	curVersion := r.Metadata().Version()
+	r.Metadata().Finalizers().Remove(fin)
+	r.Metadata().BumpVersion()
+
+	suite.Require().NoError(suite.State.Update(ctx, curVersion, r, state.WithForceFinalizerRemoval()))
+
+	suite.Assert().NoError(suite.State.Destroy(ctx, path1.Metadata()))
+}
@@ -0,0 +1,33 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package state
+
+import (
+	"context"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+)
+
+// TypeSummary describes resources of a single type in a namespace.
+type TypeSummary struct {
+	Type  resource.Type
+	Count int
+}
+
+// NamespaceSummary describes resources in a namespace.
+type NamespaceSummary struct {
+	Namespace resource.Namespace
+	Types     []TypeSummary
+}
+
+// Discovery is implemented by the states which can report their contents.
+//
+// Discovery doesn't require any prior knowledge about registered resource definitions.
+type Discovery interface {
+	// Discover returns the namespaces and types which hold resources.
+	//
+	// Namespaces and types are sorted, empty namespaces and types are skipped.
+	Discover(context.Context) ([]NamespaceSummary, error)
+}
@@ -0,0 +1,279 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package dynamic provides a type-erased client for arbitrary resource types.
+package dynamic
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/manifest"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/registry"
+)
+
+// Options for the client calls.
+type Options struct {
+	Namespace resource.Namespace
+}
+
+// Option builds Options.
+type Option func(*Options)
+
+// WithNamespace overrides the default namespace of the resource definition.
+func WithNamespace(ns resource.Namespace) Option {
+	return func(opts *Options) {
+		opts.Namespace = ns
+	}
+}
+
+// Client provides access to resources knowing only the resource type name.
+//
+// Resource types are resolved by the type, alias, singular or plural name via the resource registry.
+// Resources are returned as resource.Any.
+type Client struct {
+	state    state.State
+	registry *registry.ResourceRegistry
+}
+
+// NewClient creates new Client.
+func NewClient(st state.State, registry *registry.ResourceRegistry) *Client {
+	return &Client{
+		state:    st,
+		registry: registry,
+	}
+}
+
+// kind resolves the type name and the namespace.
+func (client *Client) kind(ctx context.Context, typeName string, opts []Option) (resource.Kind, error) {
+	var options Options
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	definition, err := client.registry.Resolve(ctx, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	ns := options.Namespace
+	if ns == "" {
+		ns = definition.DefaultNamespace
+	}
+
+	if ns == "" {
+		return nil, fmt.Errorf("namespace is not set, and resource type %q has no default namespace", definition.Type)
+	}
+
+	return resource.NewMetadata(ns, definition.Type, "", resource.VersionUndefined), nil
+}
+
+// Get a resource by type name and ID.
+func (client *Client) Get(ctx context.Context, typeName string, id resource.ID, opts ...Option) (*resource.Any, error) {
+	kind, err := client.kind(ctx, typeName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := client.state.Get(ctx, resource.NewMetadata(kind.Namespace(), kind.Type(), id, resource.VersionUndefined))
+	if err != nil {
+		return nil, err
+	}
+
+	return registry.ToAny(r)
+}
+
+// List resources by type name.
+func (client *Client) List(ctx context.Context, typeName string, opts ...Option) ([]*resource.Any, error) {
+	kind, err := client.kind(ctx, typeName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := client.state.List(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*resource.Any, 0, len(list.Items))
+
+	for _, item := range list.Items {
+		any, err := registry.ToAny(item)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, any)
+	}
+
+	return result, nil
+}
+
+// Create resources from the YAML manifest.
+//
+// Manifest might contain multiple documents, type might be specified by any name of the resource type.
+func (client *Client) Create(ctx context.Context, manifestYAML []byte) ([]*resource.Any, error) {
+	resources, err := client.decode(ctx, manifestYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*resource.Any, 0, len(resources))
+
+	for _, r := range resources {
+		if r.Metadata().Version().Equal(resource.VersionUndefined) {
+			r.Metadata().BumpVersion()
+		}
+
+		if err = client.state.Create(ctx, r); err != nil {
+			return nil, err
+		}
+
+		any, err := registry.ToAny(r)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, any)
+	}
+
+	return result, nil
+}
+
+// Update resources from the YAML manifest.
+//
+// Spec of the stored resource is replaced with the spec from the manifest, phase and finalizers are preserved.
+// If the manifest specifies resource version, it should match the stored version.
+// Resources should exist.
+func (client *Client) Update(ctx context.Context, manifestYAML []byte) ([]*resource.Any, error) {
+	resources, err := client.decode(ctx, manifestYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*resource.Any, 0, len(resources))
+
+	for _, r := range resources {
+		specYAML, err := yaml.Marshal(r.Spec())
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling spec of %s: %w", r, err)
+		}
+
+		expectedVersion := r.Metadata().Version()
+
+		var updated resource.Resource
+
+		_, err = client.state.UpdateWithConflicts(ctx, r.Metadata(), func(current resource.Resource) error {
+			if !expectedVersion.Equal(resource.VersionUndefined) && !expectedVersion.Equal(current.Metadata().Version()) {
+				return state.NewVersionConflictError(current.Metadata(), expectedVersion, current.Metadata().Version())
+			}
+
+			unmarshaler, ok := current.(resource.SpecUnmarshaler)
+			if !ok {
+				return fmt.Errorf("resource %s doesn't support spec unmarshaling", current)
+			}
+
+			updated = current
+
+			return unmarshaler.UnmarshalSpecYAML(specYAML)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		any, err := registry.ToAny(updated)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, any)
+	}
+
+	return result, nil
+}
+
+// Destroy a resource by type name and ID.
+func (client *Client) Destroy(ctx context.Context, typeName string, id resource.ID, opts ...Option) error {
+	kind, err := client.kind(ctx, typeName, opts)
+	if err != nil {
+		return err
+	}
+
+	return client.state.Destroy(ctx, resource.NewMetadata(kind.Namespace(), kind.Type(), id, resource.VersionUndefined))
+}
+
+// decode the manifest resolving resource types and default namespaces.
+func (client *Client) decode(ctx context.Context, manifestYAML []byte) ([]resource.Resource, error) {
+	decoded, err := manifest.ReadAll(bytes.NewReader(manifestYAML))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]resource.Resource, 0, len(decoded))
+
+	for _, r := range decoded {
+		md := r.Metadata()
+
+		var opts []Option
+
+		if md.Namespace() != "" {
+			opts = append(opts, WithNamespace(md.Namespace()))
+		}
+
+		kind, err := client.kind(ctx, md.Type(), opts)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved := resource.NewMetadata(kind.Namespace(), kind.Type(), md.ID(), md.Version())
+		resolved.SetPhase(md.Phase())
+
+		for _, fin := range *md.Finalizers() {
+			resolved.Finalizers().Add(fin)
+		}
+
+		*md = resolved
+
+		// resources of the types registered in this process are stored as typed resources
+		typed, err := client.registry.Types().FromAny(r.(*resource.Any))
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, typed)
+	}
+
+	return result, nil
+}
+
+// Marshal resources into the multi-document YAML.
+func Marshal(resources ...*resource.Any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(4)
+
+	for _, r := range resources {
+		out, err := resource.MarshalYAML(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = encoder.Encode(out); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
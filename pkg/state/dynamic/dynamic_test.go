@@ -0,0 +1,129 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package dynamic_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/dynamic"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/namespaced"
+	"github.com/talos-systems/os-runtime/pkg/state/registry"
+)
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	st := state.WrapCore(namespaced.NewState(inmem.Build))
+
+	resources := registry.NewResourceRegistry(st)
+	require.NoError(t, resources.RegisterDefault(ctx))
+
+	// definition registered without the Go type
+	rd, err := meta.NewResourceDefinition(meta.ResourceDefinitionSpec{
+		Type:             "Widgets.test.cosi.dev",
+		DefaultNamespace: "default",
+	})
+	require.NoError(t, err)
+	require.NoError(t, st.Create(ctx, rd))
+
+	client := dynamic.NewClient(st, resources)
+
+	created, err := client.Create(ctx, []byte(`
+metadata:
+    type: ns
+    id: user
+spec:
+    description: user namespace
+---
+metadata:
+    type: widget
+    id: w1
+spec:
+    size: 3
+`))
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+
+	assert.Equal(t, resource.Namespace("meta"), created[0].Metadata().Namespace())
+	assert.Equal(t, meta.NamespaceType, created[0].Metadata().Type())
+	assert.Equal(t, resource.Namespace("default"), created[1].Metadata().Namespace())
+	assert.Equal(t, "Widgets.test.cosi.dev", created[1].Metadata().Type())
+
+	// resources of the registered Go types are stored as typed resources
+	r, err := st.Get(ctx, created[0].Metadata())
+	require.NoError(t, err)
+	assert.IsType(t, &meta.Namespace{}, r)
+
+	ns, err := client.Get(ctx, "namespace", "user")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"description": "user namespace"}, ns.Value())
+
+	_, err = client.Get(ctx, "widgets", "w1", dynamic.WithNamespace("system"))
+	assert.True(t, state.IsNotFoundError(err))
+
+	_, err = client.Get(ctx, "gadgets", "w1")
+	assert.True(t, state.IsNotFoundError(err))
+
+	updated, err := client.Update(ctx, []byte(`
+metadata:
+    type: widgets.test.cosi.dev
+    id: w1
+    version: 1
+spec:
+    size: 5
+`))
+	require.NoError(t, err)
+	require.Len(t, updated, 1)
+	assert.Equal(t, "2", updated[0].Metadata().Version().String())
+
+	_, err = client.Update(ctx, []byte(`
+metadata:
+    type: widget
+    id: w1
+    version: 1
+spec:
+    size: 7
+`))
+	assert.True(t, state.IsConflictError(err))
+
+	_, err = client.Update(ctx, []byte(`
+metadata:
+    type: widget
+    id: w2
+spec: {}
+`))
+	assert.True(t, state.IsNotFoundError(err))
+
+	widgets, err := client.List(ctx, "widget")
+	require.NoError(t, err)
+	require.Len(t, widgets, 1)
+
+	out, err := dynamic.Marshal(widgets...)
+	require.NoError(t, err)
+	assert.Equal(t, `metadata:
+    namespace: default
+    type: Widgets.test.cosi.dev
+    id: w1
+    version: 2
+    phase: running
+spec:
+    size: 5
+`, string(out))
+
+	require.NoError(t, client.Destroy(ctx, "widget", "w1"))
+
+	widgets, err = client.List(ctx, "widget")
+	require.NoError(t, err)
+	assert.Empty(t, widgets)
+}
@@ -4,7 +4,12 @@
 
 package state
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+)
 
 // ErrNotFound should be implemented by "not found" errors.
 type ErrNotFound interface {
@@ -18,6 +23,19 @@ func IsNotFoundError(err error) bool {
 	return errors.As(err, &i)
 }
 
+type eNotFound struct {
+	error
+}
+
+func (eNotFound) NotFoundError() {}
+
+// NewNotFoundError generates error compatible with ErrNotFound.
+func NewNotFoundError(r resource.Pointer) error {
+	return eNotFound{
+		fmt.Errorf("resource %s doesn't exist", r),
+	}
+}
+
 // ErrConflict should be implemented by already exists/update conflict errors.
 type ErrConflict interface {
 	ConflictError()
@@ -29,3 +47,112 @@ func IsConflictError(err error) bool {
 
 	return errors.As(err, &i)
 }
+
+type eConflict struct {
+	error
+}
+
+func (eConflict) ConflictError() {}
+
+// NewAlreadyExistsError generates error compatible with ErrConflict.
+func NewAlreadyExistsError(r resource.Reference) error {
+	return eConflict{
+		fmt.Errorf("resource %s already exists", r),
+	}
+}
+
+// NewVersionConflictError generates error compatible with ErrConflict.
+func NewVersionConflictError(r resource.Reference, expected, found resource.Version) error {
+	return eConflict{
+		fmt.Errorf("resource %s update conflict: expected version %q, actual version %q", r, expected, found),
+	}
+}
+
+// NewUpdateSameVersionError generates error compatible with ErrConflict.
+func NewUpdateSameVersionError(r resource.Reference, version resource.Version) error {
+	return eConflict{
+		fmt.Errorf("resource %s update conflict: same %q version for new and existing objects", r, version),
+	}
+}
+
+// NewNamespaceClosedError generates error compatible with ErrConflict.
+func NewNamespaceClosedError(r resource.Reference) error {
+	return eConflict{
+		fmt.Errorf("resource %s can't be created: namespace %q is being torn down", r, r.Namespace()),
+	}
+}
+
+// NewPendingFinalizersError generates error compatible with ErrConflict.
+func NewPendingFinalizersError(r resource.Metadata) error {
+	return eConflict{
+		fmt.Errorf("resource %s has pending finalizers %s", r, r.Finalizers()),
+	}
+}
+
+// ErrPhaseTransition should be implemented by illegal phase transition errors.
+type ErrPhaseTransition interface {
+	PhaseTransitionError()
+}
+
+// IsPhaseTransitionError checks if err is illegal phase transition.
+func IsPhaseTransitionError(err error) bool {
+	var i ErrPhaseTransition
+
+	return errors.As(err, &i)
+}
+
+type ePhaseTransition struct {
+	error
+}
+
+func (ePhaseTransition) PhaseTransitionError() {}
+
+// NewPhaseTransitionError generates error compatible with ErrPhaseTransition.
+func NewPhaseTransitionError(r resource.Reference, err error) error {
+	return ePhaseTransition{
+		fmt.Errorf("resource %s update rejected: %w", r, err),
+	}
+}
+
+// ErrPermissionDenied should be implemented by errors caused by changes to resources owned by someone else.
+type ErrPermissionDenied interface {
+	PermissionDeniedError()
+}
+
+// IsPermissionDeniedError checks if err is permission denied.
+func IsPermissionDeniedError(err error) bool {
+	var i ErrPermissionDenied
+
+	return errors.As(err, &i)
+}
+
+type ePermissionDenied struct {
+	error
+}
+
+func (ePermissionDenied) PermissionDeniedError() {}
+
+// NewPermissionDeniedError generates error compatible with ErrPermissionDenied.
+func NewPermissionDeniedError(r resource.Reference, err error) error {
+	return ePermissionDenied{
+		fmt.Errorf("resource %s update rejected: %w", r, err),
+	}
+}
+
+// ErrUnexpectedType should be implemented by errors caused by resources of unexpected Go type.
+type ErrUnexpectedType interface {
+	UnexpectedTypeError()
+}
+
+// IsUnexpectedTypeError checks if err is unexpected resource Go type.
+func IsUnexpectedTypeError(err error) bool {
+	var i ErrUnexpectedType
+
+	return errors.As(err, &i)
+}
+
+type eUnexpectedType struct {
+	error
+}
+
+func (eUnexpectedType) UnexpectedTypeError() {}
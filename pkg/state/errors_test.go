@@ -0,0 +1,30 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package state_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/state"
+)
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	md := resource.NewMetadata("ns", "a", "b", resource.VersionUndefined)
+
+	assert.True(t, state.IsNotFoundError(state.NewNotFoundError(md)))
+	assert.True(t, state.IsConflictError(state.NewAlreadyExistsError(md)))
+	assert.True(t, state.IsConflictError(state.NewVersionConflictError(md, resource.VersionUndefined, resource.VersionUndefined)))
+	assert.True(t, state.IsConflictError(state.NewUpdateSameVersionError(md, resource.VersionUndefined)))
+	assert.True(t, state.IsConflictError(state.NewPendingFinalizersError(md)))
+	assert.True(t, state.IsConflictError(state.NewNamespaceClosedError(md)))
+	assert.True(t, state.IsPhaseTransitionError(state.NewPhaseTransitionError(md, errors.New("phase"))))
+	assert.True(t, state.IsPermissionDeniedError(state.NewPermissionDeniedError(md, errors.New("owner"))))
+}
@@ -0,0 +1,616 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package cache provides an implementation of state.State which mirrors a remote state in memory.
+//
+// Resource kinds are mirrored on first access with WatchKind on the remote state,
+// reads and watches are served from memory, and writes are forwarded to the remote state.
+// Writes return once the written version is delivered to the mirror, so that reads
+// on the same State always observe the writes done through it.
+//
+// Mirrors are periodically compared with the remote state: if the mirror doesn't catch up
+// with the remote state between two checks, the remote watch is considered stopped,
+// and the mirror bootstraps itself again, retrying with a backoff. Watches on the State
+// keep running across the restarts of the mirror.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/namespaced"
+)
+
+// Options configure the State.
+type Options struct {
+	ResyncInterval   time.Duration
+	BootstrapTimeout time.Duration
+	RetryInterval    time.Duration
+}
+
+// Option builds Options.
+type Option func(*Options)
+
+// WithResyncInterval sets the interval between the comparisons of the mirrors with the remote state.
+func WithResyncInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.ResyncInterval = interval
+	}
+}
+
+// WithBootstrapTimeout sets the time limit for the mirror to catch up with the remote state.
+//
+// Reads which wait for the mirror to be bootstrapped fail once the timeout expires.
+func WithBootstrapTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.BootstrapTimeout = timeout
+	}
+}
+
+// WithRetryInterval sets the initial interval between the attempts to bootstrap the mirror.
+//
+// The interval is doubled on each failed attempt, up to the resync interval.
+func WithRetryInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.RetryInterval = interval
+	}
+}
+
+type kindKey struct {
+	ns  resource.Namespace
+	typ resource.Type
+}
+
+func keyOf(kind resource.Kind) kindKey {
+	return kindKey{
+		ns:  kind.Namespace(),
+		typ: kind.Type(),
+	}
+}
+
+// bootstrap is an attempt to bootstrap the mirror.
+type bootstrap struct {
+	done chan struct{}
+	err  error
+}
+
+func newBootstrap() *bootstrap {
+	return &bootstrap{
+		done: make(chan struct{}),
+	}
+}
+
+func (b *bootstrap) finish(err error) {
+	b.err = err
+	close(b.done)
+}
+
+type mirror struct {
+	// current bootstrap attempt, protected by State.mu
+	bootstrap *bootstrap
+}
+
+// waiter waits for the write to be delivered to the mirror.
+type waiter struct {
+	kind      kindKey
+	id        resource.ID
+	version   resource.Version
+	destroyed bool
+
+	ch chan struct{}
+}
+
+func (w *waiter) matches(event state.Event) bool {
+	md := event.Resource.Metadata()
+
+	if keyOf(md) != w.kind || md.ID() != w.id || event.Type == state.Errored {
+		return false
+	}
+
+	if w.destroyed {
+		return event.Type == state.Destroyed
+	}
+
+	return event.Type != state.Destroyed && md.Version().Equal(w.version)
+}
+
+// State implements state.CoreState caching the remote state.
+type State struct {
+	ctx     context.Context
+	remote  state.CoreState
+	local   *namespaced.State
+	options Options
+
+	mu      sync.Mutex
+	mirrors map[kindKey]*mirror
+	waiters map[*waiter]struct{}
+}
+
+// NewState creates new State.
+//
+// Mirroring is stopped when the context is canceled.
+func NewState(ctx context.Context, remote state.CoreState, opts ...Option) *State {
+	options := Options{
+		ResyncInterval:   time.Minute,
+		BootstrapTimeout: 10 * time.Second,
+		RetryInterval:    time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &State{
+		ctx:     ctx,
+		remote:  remote,
+		local:   namespaced.NewState(inmem.Build),
+		options: options,
+		mirrors: map[kindKey]*mirror{},
+		waiters: map[*waiter]struct{}{},
+	}
+}
+
+// ensure starts mirroring the resource kind and waits for the mirror to catch up.
+func (st *State) ensure(ctx context.Context, kind resource.Kind) error {
+	st.mu.Lock()
+
+	m, ok := st.mirrors[keyOf(kind)]
+	if !ok {
+		m = &mirror{
+			bootstrap: newBootstrap(),
+		}
+
+		st.mirrors[keyOf(kind)] = m
+
+		go st.runMirror(kind, m)
+	}
+
+	b := m.bootstrap
+
+	st.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.done:
+	}
+
+	return b.err
+}
+
+// runMirror keeps the mirror in sync with the remote state, restarting it when it fails.
+func (st *State) runMirror(kind resource.Kind, m *mirror) {
+	st.mu.Lock()
+	b := m.bootstrap
+	st.mu.Unlock()
+
+	var delay time.Duration
+
+	for {
+		ready, err := st.sync(st.ctx, kind, b)
+
+		if !ready {
+			b.finish(err)
+		}
+
+		// writes are done, even though they might be not delivered to the mirror
+		st.release(keyOf(kind))
+
+		if st.ctx.Err() != nil {
+			st.restart(m).finish(st.ctx.Err())
+
+			return
+		}
+
+		switch {
+		case ready:
+			// the mirror was in sync, so it's restarted immediately
+			delay = 0
+		case delay == 0:
+			delay = st.options.RetryInterval
+		default:
+			delay *= 2
+
+			if delay > st.options.ResyncInterval {
+				delay = st.options.ResyncInterval
+			}
+		}
+
+		if delay > 0 {
+			select {
+			case <-st.ctx.Done():
+				st.restart(m).finish(st.ctx.Err())
+
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		b = st.restart(m)
+	}
+}
+
+// restart installs a new bootstrap attempt for the mirror.
+func (st *State) restart(m *mirror) *bootstrap {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	m.bootstrap = newBootstrap()
+
+	return m.bootstrap
+}
+
+// release the writers waiting for the resources of the kind.
+func (st *State) release(kind kindKey) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for w := range st.waiters {
+		if w.kind == kind {
+			close(w.ch)
+
+			delete(st.waiters, w)
+		}
+	}
+}
+
+// sync bootstraps the mirror and keeps it in sync until the remote watch fails.
+//
+// sync finishes the bootstrap attempt once the mirror catches up with the remote state,
+// and reports whether it did.
+func (st *State) sync(ctx context.Context, kind resource.Kind, b *bootstrap) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan state.Event)
+
+	if err := st.remote.WatchKind(ctx, kind, events, state.WithBootstrapContents(true)); err != nil {
+		return false, err
+	}
+
+	// the mirror is ready once it catches up with the list taken after the watch was started
+	list, err := st.remote.List(ctx, kind)
+	if err != nil {
+		return false, err
+	}
+
+	pending := make(map[resource.ID]resource.Version, len(list.Items))
+
+	for _, r := range list.Items {
+		pending[r.Metadata().ID()] = r.Metadata().Version()
+	}
+
+	// resources destroyed while the kind was not mirrored
+	if err = st.prune(ctx, kind, pending); err != nil {
+		return false, err
+	}
+
+	ready := false
+
+	timeout := time.NewTimer(st.options.BootstrapTimeout)
+	defer timeout.Stop()
+
+	timeoutCh := timeout.C
+
+	if len(pending) == 0 {
+		ready = true
+		timeoutCh = nil
+
+		b.finish(nil)
+	}
+
+	ticker := time.NewTicker(st.options.ResyncInterval)
+	defer ticker.Stop()
+
+	var lagging map[resource.ID]string
+
+	for {
+		var event state.Event
+
+		select {
+		case <-ctx.Done():
+			return ready, ctx.Err()
+		case <-timeoutCh:
+			return ready, fmt.Errorf("mirror of resources %q in namespace %q didn't catch up with the remote state in %s", kind.Type(), kind.Namespace(), st.options.BootstrapTimeout)
+		case <-ticker.C:
+			if lagging, err = st.check(ctx, kind, lagging); err != nil {
+				return ready, err
+			}
+
+			continue
+		case event = <-events:
+		}
+
+		if err = st.apply(ctx, event); err != nil {
+			if err = st.resync(ctx, event.Resource.Metadata()); err != nil {
+				return ready, err
+			}
+		}
+
+		if !ready {
+			id := event.Resource.Metadata().ID()
+
+			if version, ok := pending[id]; ok && (event.Type == state.Destroyed || event.Resource.Metadata().Version().Equal(version)) {
+				delete(pending, id)
+
+				if len(pending) == 0 {
+					ready = true
+					timeoutCh = nil
+
+					b.finish(nil)
+				}
+			}
+		}
+
+		st.notify(event)
+	}
+}
+
+// notify the writers waiting for the event.
+func (st *State) notify(event state.Event) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for w := range st.waiters {
+		if w.matches(event) {
+			close(w.ch)
+
+			delete(st.waiters, w)
+		}
+	}
+}
+
+// apply the remote event to the local state.
+func (st *State) apply(ctx context.Context, event state.Event) error {
+	switch event.Type {
+	case state.Created, state.Updated:
+		return st.store(ctx, event.Resource)
+	case state.Destroyed:
+		if err := st.local.Destroy(ctx, event.Resource.Metadata()); err != nil && !state.IsNotFoundError(err) {
+			return err
+		}
+	case state.Errored:
+		return event.Error
+	}
+
+	return nil
+}
+
+// resync the resource from the remote state.
+func (st *State) resync(ctx context.Context, ptr resource.Pointer) error {
+	r, err := st.remote.Get(ctx, ptr)
+	if err != nil {
+		if state.IsNotFoundError(err) {
+			return st.remove(ctx, ptr)
+		}
+
+		return err
+	}
+
+	return st.store(ctx, r)
+}
+
+// store the remote resource in the local state.
+func (st *State) store(ctx context.Context, r resource.Resource) error {
+	current, err := st.local.Get(ctx, r.Metadata())
+	if err != nil {
+		if state.IsNotFoundError(err) {
+			return st.local.Create(ctx, r)
+		}
+
+		return err
+	}
+
+	if current.Metadata().Version().Equal(r.Metadata().Version()) {
+		return nil
+	}
+
+	return st.local.Update(ctx, current.Metadata().Version(), r, state.WithForcePhaseTransition(), state.WithForceFinalizerRemoval())
+}
+
+// remove the resource from the local state, even if it has finalizers.
+func (st *State) remove(ctx context.Context, ptr resource.Pointer) error {
+	current, err := st.local.Get(ctx, ptr)
+	if err != nil {
+		if state.IsNotFoundError(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if !current.Metadata().Finalizers().Empty() {
+		cleared := current.DeepCopy()
+		*cleared.Metadata().Finalizers() = nil
+		cleared.Metadata().BumpVersion()
+
+		if err = st.local.Update(ctx, current.Metadata().Version(), cleared, state.WithForcePhaseTransition(), state.WithForceFinalizerRemoval()); err != nil {
+			return err
+		}
+	}
+
+	if err = st.local.Destroy(ctx, ptr); err != nil && !state.IsNotFoundError(err) {
+		return err
+	}
+
+	return nil
+}
+
+// prune removes the local resources of the kind which are not in the remote state.
+func (st *State) prune(ctx context.Context, kind resource.Kind, remote map[resource.ID]resource.Version) error {
+	list, err := st.local.List(ctx, kind)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range list.Items {
+		if _, ok := remote[r.Metadata().ID()]; ok {
+			continue
+		}
+
+		if err = st.remove(ctx, r.Metadata()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// check compares the mirror with the remote state.
+//
+// check returns the resources which differ between the remote state and the mirror,
+// along with their versions in the mirror (empty if the resource is missing).
+// If any of the resources lagging on the previous check hasn't changed in the mirror since then,
+// the remote watch is considered stopped.
+func (st *State) check(ctx context.Context, kind resource.Kind, lagging map[resource.ID]string) (map[resource.ID]string, error) {
+	remote, err := st.remote.List(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := st.local.List(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[resource.ID]string, len(local.Items))
+
+	for _, r := range local.Items {
+		versions[r.Metadata().ID()] = r.Metadata().Version().String()
+	}
+
+	diverged := map[resource.ID]string{}
+
+	for _, r := range remote.Items {
+		id := r.Metadata().ID()
+
+		if version, ok := versions[id]; !ok || version != r.Metadata().Version().String() {
+			diverged[id] = version
+		}
+
+		delete(versions, id)
+	}
+
+	for id, version := range versions {
+		diverged[id] = version
+	}
+
+	for id, version := range diverged {
+		if previous, ok := lagging[id]; ok && previous == version {
+			return nil, fmt.Errorf("remote watch of resources %q in namespace %q stopped", kind.Type(), kind.Namespace())
+		}
+	}
+
+	return diverged, nil
+}
+
+// write forwards the write to the remote state and waits for it to be delivered to the mirror.
+//
+// Once the remote write succeeds, its result is returned, even if the mirror is restarted
+// (or the context is canceled) before the write is delivered.
+func (st *State) write(ctx context.Context, w *waiter, f func() error) error {
+	w.ch = make(chan struct{})
+
+	st.mu.Lock()
+	st.waiters[w] = struct{}{}
+	st.mu.Unlock()
+
+	err := f()
+
+	st.mu.Lock()
+	_, mirrored := st.mirrors[w.kind]
+
+	if err != nil || !mirrored {
+		delete(st.waiters, w)
+		st.mu.Unlock()
+
+		return err
+	}
+
+	st.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		st.mu.Lock()
+		delete(st.waiters, w)
+		st.mu.Unlock()
+	case <-w.ch:
+	}
+
+	return nil
+}
+
+// Get a resource.
+func (st *State) Get(ctx context.Context, resourcePointer resource.Pointer, opts ...state.GetOption) (resource.Resource, error) {
+	if err := st.ensure(ctx, resourcePointer); err != nil {
+		return nil, err
+	}
+
+	return st.local.Get(ctx, resourcePointer, opts...)
+}
+
+// List resources.
+func (st *State) List(ctx context.Context, resourceKind resource.Kind, opts ...state.ListOption) (resource.List, error) {
+	if err := st.ensure(ctx, resourceKind); err != nil {
+		return resource.List{}, err
+	}
+
+	return st.local.List(ctx, resourceKind, opts...)
+}
+
+// Create a resource.
+func (st *State) Create(ctx context.Context, r resource.Resource, opts ...state.CreateOption) error {
+	return st.write(ctx, &waiter{
+		kind:    keyOf(r.Metadata()),
+		id:      r.Metadata().ID(),
+		version: r.Metadata().Version(),
+	}, func() error {
+		return st.remote.Create(ctx, r, opts...)
+	})
+}
+
+// Update a resource.
+func (st *State) Update(ctx context.Context, curVersion resource.Version, newResource resource.Resource, opts ...state.UpdateOption) error {
+	return st.write(ctx, &waiter{
+		kind:    keyOf(newResource.Metadata()),
+		id:      newResource.Metadata().ID(),
+		version: newResource.Metadata().Version(),
+	}, func() error {
+		return st.remote.Update(ctx, curVersion, newResource, opts...)
+	})
+}
+
+// Destroy a resource.
+func (st *State) Destroy(ctx context.Context, resourcePointer resource.Pointer, opts ...state.DestroyOption) error {
+	return st.write(ctx, &waiter{
+		kind:      keyOf(resourcePointer),
+		id:        resourcePointer.ID(),
+		destroyed: true,
+	}, func() error {
+		return st.remote.Destroy(ctx, resourcePointer, opts...)
+	})
+}
+
+// Watch a resource.
+func (st *State) Watch(ctx context.Context, resourcePointer resource.Pointer, ch chan<- state.Event, opts ...state.WatchOption) error {
+	if err := st.ensure(ctx, resourcePointer); err != nil {
+		return err
+	}
+
+	return st.local.Watch(ctx, resourcePointer, ch, opts...)
+}
+
+// WatchKind all resources by type.
+func (st *State) WatchKind(ctx context.Context, resourceKind resource.Kind, ch chan<- state.Event, opts ...state.WatchKindOption) error {
+	if err := st.ensure(ctx, resourceKind); err != nil {
+		return err
+	}
+
+	return st.local.WatchKind(ctx, resourceKind, ch, opts...)
+}
@@ -0,0 +1,258 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/conformance"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/cache"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/namespaced"
+)
+
+// countingState counts reads hitting the remote state.
+type countingState struct {
+	state.CoreState
+
+	reads int64
+}
+
+func (st *countingState) Get(ctx context.Context, ptr resource.Pointer, opts ...state.GetOption) (resource.Resource, error) {
+	atomic.AddInt64(&st.reads, 1)
+
+	return st.CoreState.Get(ctx, ptr, opts...)
+}
+
+func (st *countingState) List(ctx context.Context, kind resource.Kind, opts ...state.ListOption) (resource.List, error) {
+	atomic.AddInt64(&st.reads, 1)
+
+	return st.CoreState.List(ctx, kind, opts...)
+}
+
+func TestInterfaces(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*state.CoreState)(nil), new(cache.State))
+}
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	suite.Run(t, &conformance.StateSuite{
+		State:      state.WrapCore(cache.NewState(ctx, namespaced.NewState(inmem.Build))),
+		Namespaces: []resource.Namespace{"default", "controller", "system", "runtime"},
+	})
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	remote := &countingState{
+		CoreState: namespaced.NewState(inmem.Build),
+	}
+
+	// resources existing before the cache was created
+	for _, path := range []string{"/var", "/etc"} {
+		require.NoError(t, remote.Create(ctx, conformance.NewPathResource("default", path)))
+	}
+
+	st := state.WrapCore(cache.NewState(ctx, remote))
+
+	list, err := st.List(ctx, conformance.NewPathResource("default", "").Metadata())
+	require.NoError(t, err)
+	require.Len(t, list.Items, 2)
+
+	// the initial list of the mirror
+	reads := atomic.LoadInt64(&remote.reads)
+
+	for i := 0; i < 10; i++ {
+		_, err = st.Get(ctx, conformance.NewPathResource("default", "/var").Metadata())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, reads, atomic.LoadInt64(&remote.reads))
+
+	// read-your-writes
+	require.NoError(t, st.Create(ctx, conformance.NewPathResource("default", "/usr")))
+
+	_, err = st.Get(ctx, conformance.NewPathResource("default", "/usr").Metadata())
+	require.NoError(t, err)
+
+	require.NoError(t, st.AddFinalizer(ctx, conformance.NewPathResource("default", "/usr").Metadata(), "A"))
+
+	r, err := st.Get(ctx, conformance.NewPathResource("default", "/usr").Metadata())
+	require.NoError(t, err)
+	assert.Equal(t, resource.Finalizers{"A"}, *r.Metadata().Finalizers())
+
+	require.NoError(t, st.RemoveFinalizer(ctx, conformance.NewPathResource("default", "/usr").Metadata(), "A"))
+	require.NoError(t, st.Destroy(ctx, conformance.NewPathResource("default", "/usr").Metadata()))
+
+	_, err = st.Get(ctx, conformance.NewPathResource("default", "/usr").Metadata())
+	assert.True(t, state.IsNotFoundError(err))
+
+	// writes done directly to the remote state are delivered to the cache
+	require.NoError(t, remote.Destroy(ctx, conformance.NewPathResource("default", "/etc").Metadata()))
+
+	_, err = st.WatchFor(ctx, conformance.NewPathResource("default", "/etc").Metadata(), state.WithEventTypes(state.Destroyed))
+	require.NoError(t, err)
+
+	list, err = st.List(ctx, conformance.NewPathResource("default", "").Metadata())
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "/var", list.Items[0].Metadata().ID())
+
+	assert.Equal(t, reads, atomic.LoadInt64(&remote.reads))
+}
+
+// stoppingState stops delivering watch events on demand.
+type stoppingState struct {
+	state.CoreState
+
+	mu   sync.Mutex
+	stop chan struct{}
+}
+
+func (st *stoppingState) WatchKind(ctx context.Context, kind resource.Kind, ch chan<- state.Event, opts ...state.WatchKindOption) error {
+	st.mu.Lock()
+	stop := st.stop
+	st.mu.Unlock()
+
+	events := make(chan state.Event)
+
+	if err := st.CoreState.WatchKind(ctx, kind, events, opts...); err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			var event state.Event
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case event = <-events:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- event:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// stopWatches stops the established watches.
+func (st *stoppingState) stopWatches() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	close(st.stop)
+	st.stop = make(chan struct{})
+}
+
+func TestStoppedWatch(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	remote := &stoppingState{
+		CoreState: namespaced.NewState(inmem.Build),
+		stop:      make(chan struct{}),
+	}
+
+	st := state.WrapCore(cache.NewState(ctx, remote, cache.WithResyncInterval(10*time.Millisecond), cache.WithRetryInterval(10*time.Millisecond)))
+
+	require.NoError(t, st.Create(ctx, conformance.NewPathResource("default", "/var")))
+	require.NoError(t, st.AddFinalizer(ctx, conformance.NewPathResource("default", "/var").Metadata(), "A"))
+
+	ch := make(chan state.Event)
+	require.NoError(t, st.WatchKind(ctx, conformance.NewPathResource("default", "").Metadata(), ch))
+
+	remote.stopWatches()
+
+	// write is not delivered to the mirror, but it succeeded in the remote state
+	require.NoError(t, st.Create(ctx, conformance.NewPathResource("default", "/etc")))
+
+	direct := state.WrapCore(remote.CoreState)
+
+	require.NoError(t, direct.RemoveFinalizer(ctx, conformance.NewPathResource("default", "/var").Metadata(), "A"))
+	require.NoError(t, direct.Destroy(ctx, conformance.NewPathResource("default", "/var").Metadata()))
+
+	// mirror restarts itself, and the watch established before the restart gets the missed changes
+	var created, destroyed bool
+
+	for !created || !destroyed {
+		select {
+		case event := <-ch:
+			switch {
+			case event.Type == state.Created && event.Resource.Metadata().ID() == "/etc":
+				created = true
+			case event.Type == state.Destroyed && event.Resource.Metadata().ID() == "/var":
+				destroyed = true
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for events")
+		}
+	}
+
+	list, err := st.List(ctx, conformance.NewPathResource("default", "").Metadata())
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "/etc", list.Items[0].Metadata().ID())
+
+	require.NoError(t, st.Create(ctx, conformance.NewPathResource("default", "/usr")))
+
+	_, err = st.Get(ctx, conformance.NewPathResource("default", "/usr").Metadata())
+	require.NoError(t, err)
+}
+
+// silentState never delivers watch events.
+type silentState struct {
+	state.CoreState
+}
+
+func (silentState) WatchKind(context.Context, resource.Kind, chan<- state.Event, ...state.WatchKindOption) error {
+	return nil
+}
+
+func TestBootstrapTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	remote := silentState{namespaced.NewState(inmem.Build)}
+
+	require.NoError(t, remote.Create(ctx, conformance.NewPathResource("default", "/var")))
+
+	st := state.WrapCore(cache.NewState(ctx, remote, cache.WithBootstrapTimeout(50*time.Millisecond), cache.WithRetryInterval(10*time.Millisecond)))
+
+	_, err := st.Get(ctx, conformance.NewPathResource("default", "/var").Metadata())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "didn't catch up")
+}
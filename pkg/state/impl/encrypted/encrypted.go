@@ -0,0 +1,399 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package encrypted provides a state.CoreState wrapper which encrypts specs of sensitive resources at rest.
+//
+// Resource types are sensitive if their ResourceDefinition has Sensitive flag set.
+// Specs of sensitive resources are encrypted with AES-256-GCM before they reach the underlying state,
+// and they are stored as resource.Any with the encrypted spec. Metadata is stored unencrypted.
+package encrypted
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/registry"
+)
+
+const algorithm = "AES-256-GCM"
+
+type envelope struct {
+	Encryption *encryption `yaml:"encryption"`
+}
+
+type encryption struct {
+	Algorithm string `yaml:"algorithm"`
+	KeyID     string `yaml:"keyID"`
+	Nonce     string `yaml:"nonce"`
+	Data      string `yaml:"data"`
+}
+
+// State implements state.CoreState encrypting specs of the sensitive resources.
+type State struct {
+	core  state.CoreState
+	types *registry.TypeRegistry
+	keys  KeyProvider
+}
+
+// NewState creates new State.
+//
+// Type registry is used to find sensitive resource types, and to decode decrypted resources.
+func NewState(core state.CoreState, types *registry.TypeRegistry, keys KeyProvider) *State {
+	return &State{
+		core:  core,
+		types: types,
+		keys:  keys,
+	}
+}
+
+func (st *State) sensitive(typ resource.Type) bool {
+	definition, ok := st.types.Definition(typ)
+
+	return ok && definition.Sensitive
+}
+
+// additionalData binds the encrypted spec to the resource it belongs to.
+func additionalData(md *resource.Metadata) []byte {
+	return []byte(fmt.Sprintf("%s/%s/%s", md.Namespace(), md.Type(), md.ID()))
+}
+
+func newAEAD(key Key) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing cipher with key %q: %w", key.ID, err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// envelopeOf returns encryption details of the stored resource, or nil if the resource is not encrypted.
+//
+// Only resources of sensitive types are encrypted, specs of other resources are never treated as envelopes.
+func (st *State) envelopeOf(r resource.Resource) (*encryption, error) {
+	if !st.sensitive(r.Metadata().Type()) {
+		return nil, nil
+	}
+
+	any, ok := r.(*resource.Any)
+	if !ok {
+		return nil, nil
+	}
+
+	specYAML, err := yaml.Marshal(any.Spec())
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling spec of %s: %w", r, err)
+	}
+
+	var env envelope
+
+	// specs which don't match the envelope are not encrypted
+	if yaml.Unmarshal(specYAML, &env) != nil || env.Encryption == nil || env.Encryption.Algorithm != algorithm {
+		return nil, nil
+	}
+
+	return env.Encryption, nil
+}
+
+func (st *State) encrypt(ctx context.Context, r resource.Resource) (resource.Resource, error) {
+	if !st.sensitive(r.Metadata().Type()) {
+		return r, nil
+	}
+
+	key, err := st.keys.PrimaryKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting encryption key: %w", err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := yaml.Marshal(r.Spec())
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling spec of %s: %w", r, err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	envelopeYAML, err := yaml.Marshal(envelope{
+		Encryption: &encryption{
+			Algorithm: algorithm,
+			KeyID:     key.ID,
+			Nonce:     base64.StdEncoding.EncodeToString(nonce),
+			Data:      base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plaintext, additionalData(r.Metadata()))),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted := &resource.Any{}
+	*encrypted.Metadata() = r.Metadata().Copy()
+
+	if err = encrypted.UnmarshalSpecYAML(envelopeYAML); err != nil {
+		return nil, err
+	}
+
+	return encrypted, nil
+}
+
+func (st *State) decrypt(ctx context.Context, r resource.Resource) (resource.Resource, error) {
+	enc, err := st.envelopeOf(r)
+	if err != nil || enc == nil {
+		return r, err
+	}
+
+	key, err := st.keys.Key(ctx, enc.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting decryption key for %s: %w", r.Metadata(), err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(enc.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding nonce of %s: %w", r.Metadata(), err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(enc.Data)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding spec of %s: %w", r.Metadata(), err)
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("error decrypting spec of %s: unexpected nonce size %d", r.Metadata(), len(nonce))
+	}
+
+	plaintext, err := aead.Open(nil, nonce, data, additionalData(r.Metadata()))
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting spec of %s: %w", r.Metadata(), err)
+	}
+
+	decrypted, ok := st.types.New(r.Metadata().Type())
+	if !ok {
+		decrypted = &resource.Any{}
+	}
+
+	unmarshaler, ok := decrypted.(resource.SpecUnmarshaler)
+	if !ok {
+		return nil, fmt.Errorf("resource %s doesn't support spec unmarshaling", decrypted)
+	}
+
+	*decrypted.Metadata() = r.Metadata().Copy()
+
+	if err = unmarshaler.UnmarshalSpecYAML(plaintext); err != nil {
+		return nil, fmt.Errorf("error decoding spec of %s: %w", r.Metadata(), err)
+	}
+
+	return decrypted, nil
+}
+
+// Get a resource.
+func (st *State) Get(ctx context.Context, resourcePointer resource.Pointer, opts ...state.GetOption) (resource.Resource, error) {
+	r, err := st.core.Get(ctx, resourcePointer, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return st.decrypt(ctx, r)
+}
+
+// List resources.
+func (st *State) List(ctx context.Context, resourceKind resource.Kind, opts ...state.ListOption) (resource.List, error) {
+	list, err := st.core.List(ctx, resourceKind, opts...)
+	if err != nil {
+		return resource.List{}, err
+	}
+
+	for i := range list.Items {
+		if list.Items[i], err = st.decrypt(ctx, list.Items[i]); err != nil {
+			return resource.List{}, err
+		}
+	}
+
+	return list, nil
+}
+
+// Create a resource.
+func (st *State) Create(ctx context.Context, r resource.Resource, opts ...state.CreateOption) error {
+	encrypted, err := st.encrypt(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return st.core.Create(ctx, encrypted, opts...)
+}
+
+// Update a resource.
+func (st *State) Update(ctx context.Context, curVersion resource.Version, newResource resource.Resource, opts ...state.UpdateOption) error {
+	encrypted, err := st.encrypt(ctx, newResource)
+	if err != nil {
+		return err
+	}
+
+	return st.core.Update(ctx, curVersion, encrypted, opts...)
+}
+
+// Destroy a resource.
+func (st *State) Destroy(ctx context.Context, resourcePointer resource.Pointer, opts ...state.DestroyOption) error {
+	return st.core.Destroy(ctx, resourcePointer, opts...)
+}
+
+// Watch a resource.
+func (st *State) Watch(ctx context.Context, resourcePointer resource.Pointer, ch chan<- state.Event, opts ...state.WatchOption) error {
+	inner := make(chan state.Event)
+
+	if err := st.core.Watch(ctx, resourcePointer, inner, opts...); err != nil {
+		return err
+	}
+
+	go st.decryptEvents(ctx, inner, ch)
+
+	return nil
+}
+
+// WatchKind all resources by type.
+func (st *State) WatchKind(ctx context.Context, resourceKind resource.Kind, ch chan<- state.Event, opts ...state.WatchKindOption) error {
+	inner := make(chan state.Event)
+
+	if err := st.core.WatchKind(ctx, resourceKind, inner, opts...); err != nil {
+		return err
+	}
+
+	go st.decryptEvents(ctx, inner, ch)
+
+	return nil
+}
+
+// decryptEvents delivers decrypted events.
+//
+// Changes of the resources which can't be decrypted (e.g. the key was removed) are delivered
+// as state.Errored events, destroyed resources are delivered as tombstones.
+func (st *State) decryptEvents(ctx context.Context, in <-chan state.Event, out chan<- state.Event) {
+	for {
+		var event state.Event
+
+		select {
+		case <-ctx.Done():
+			return
+		case event = <-in:
+		}
+
+		decrypted, err := st.decrypt(ctx, event.Resource)
+
+		switch {
+		case err == nil:
+			event.Resource = decrypted
+		case event.Type == state.Destroyed:
+			// metadata is not encrypted, so the watcher still learns about the destroyed resource
+			event.Resource = resource.NewTombstone(event.Resource.Metadata())
+		default:
+			event = state.Event{
+				Type:     state.Errored,
+				Resource: resource.NewTombstone(event.Resource.Metadata()),
+				Error:    err,
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case out <- event:
+		}
+	}
+}
+
+// Reencrypt resources of the sensitive kind which are not encrypted with the primary key.
+//
+// Reencrypt should be called after the primary key is rotated, and before the previous key is removed.
+// Unencrypted resources are encrypted as well. Resources get a new version on reencryption.
+// Reencrypt returns the number of reencrypted resources.
+func (st *State) Reencrypt(ctx context.Context, resourceKind resource.Kind) (int, error) {
+	if !st.sensitive(resourceKind.Type()) {
+		return 0, fmt.Errorf("resource type %q is not sensitive", resourceKind.Type())
+	}
+
+	list, err := st.core.List(ctx, resourceKind)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, r := range list.Items {
+		reencrypted, err := st.reencrypt(ctx, r)
+		if err != nil {
+			return count, err
+		}
+
+		if reencrypted {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (st *State) reencrypt(ctx context.Context, stored resource.Resource) (bool, error) {
+	for {
+		key, err := st.keys.PrimaryKey(ctx)
+		if err != nil {
+			return false, fmt.Errorf("error getting encryption key: %w", err)
+		}
+
+		enc, err := st.envelopeOf(stored)
+		if err != nil {
+			return false, err
+		}
+
+		if enc != nil && enc.KeyID == key.ID {
+			return false, nil
+		}
+
+		r, err := st.decrypt(ctx, stored)
+		if err != nil {
+			return false, err
+		}
+
+		curVersion := r.Metadata().Version()
+
+		r.Metadata().BumpVersion()
+
+		err = st.Update(ctx, curVersion, r)
+
+		switch {
+		case err == nil:
+			return true, nil
+		case state.IsNotFoundError(err):
+			return false, nil
+		case !state.IsConflictError(err):
+			return false, err
+		}
+
+		// resource was updated concurrently
+		if stored, err = st.core.Get(ctx, stored.Metadata()); err != nil {
+			if state.IsNotFoundError(err) {
+				return false, nil
+			}
+
+			return false, err
+		}
+	}
+}
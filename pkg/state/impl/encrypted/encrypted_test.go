@@ -0,0 +1,292 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encrypted_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/conformance"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/encrypted"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/namespaced"
+	"github.com/talos-systems/os-runtime/pkg/state/registry"
+)
+
+func writeKeys(t *testing.T, path, primary string, ids ...string) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "primary: %s\nkeys:\n", primary)
+
+	for _, id := range ids {
+		// deterministic keys derived from the ID
+		secret := bytes.Repeat([]byte(id[len(id)-1:]), encrypted.KeySize)
+
+		fmt.Fprintf(&buf, "  - id: %s\n    secret: %s\n", id, base64.StdEncoding.EncodeToString(secret))
+	}
+
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+}
+
+func newKeyProvider(t *testing.T) (*encrypted.FileKeyProvider, string) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+
+	writeKeys(t, path, "key-1", "key-1")
+
+	keys, err := encrypted.NewFileKeyProvider(path)
+	require.NoError(t, err)
+
+	return keys, path
+}
+
+func sensitiveTypes(t *testing.T) *registry.TypeRegistry {
+	types := registry.NewTypeRegistry()
+
+	pathDefinition := conformance.PathExtension{}.ResourceDefinition()
+	pathDefinition.Sensitive = true
+
+	require.NoError(t, types.Register(pathDefinition, registry.FactoryFor(conformance.NewPathResource("", ""))))
+
+	nsDefinition := meta.NamespaceExtension{}.ResourceDefinition()
+	nsDefinition.Sensitive = true
+
+	require.NoError(t, types.Register(nsDefinition, registry.FactoryFor(meta.NewNamespace("", meta.NamespaceSpec{}))))
+
+	return types
+}
+
+func TestInterfaces(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*state.CoreState)(nil), new(encrypted.State))
+	assert.Implements(t, (*encrypted.KeyProvider)(nil), new(encrypted.FileKeyProvider))
+}
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	keys, _ := newKeyProvider(t)
+
+	suite.Run(t, &conformance.StateSuite{
+		State:      state.WrapCore(encrypted.NewState(namespaced.NewState(inmem.Build), sensitiveTypes(t), keys)),
+		Namespaces: []resource.Namespace{"default", "controller", "system", "runtime"},
+	})
+}
+
+func TestEncryption(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys, keysPath := newKeyProvider(t)
+
+	core := namespaced.NewState(inmem.Build)
+	st := state.WrapCore(encrypted.NewState(core, sensitiveTypes(t), keys))
+
+	ns := meta.NewNamespace("user", meta.NamespaceSpec{Description: "top secret"})
+
+	ch := make(chan state.Event)
+
+	require.NoError(t, st.Watch(ctx, ns.Metadata(), ch))
+
+	<-ch
+
+	require.NoError(t, st.Create(ctx, ns))
+
+	// watch events are decrypted
+	event := <-ch
+	assert.Equal(t, state.Created, event.Type)
+	assert.Equal(t, "top secret", event.Resource.(*meta.Namespace).TypedSpec().Description)
+
+	// spec is not stored in plaintext
+	stored, err := core.Get(ctx, ns.Metadata())
+	require.NoError(t, err)
+
+	dump, err := yaml.Marshal(stored.Spec())
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(dump), "top secret")
+	assert.Contains(t, string(dump), "keyID: key-1")
+
+	r, err := st.Get(ctx, ns.Metadata())
+	require.NoError(t, err)
+	assert.Equal(t, "top secret", r.(*meta.Namespace).TypedSpec().Description)
+
+	// non-sensitive resources are stored as is
+	require.NoError(t, core.Create(ctx, conformance.NewPathResource("default", "/var")))
+
+	// rotate the primary key
+	writeKeys(t, keysPath, "key-2", "key-1", "key-2")
+	require.NoError(t, keys.Reload())
+
+	count, err := encrypted.NewState(core, sensitiveTypes(t), keys).Reencrypt(ctx, ns.Metadata())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	event = <-ch
+	assert.Equal(t, state.Updated, event.Type)
+	assert.Equal(t, "top secret", event.Resource.(*meta.Namespace).TypedSpec().Description)
+
+	stored, err = core.Get(ctx, ns.Metadata())
+	require.NoError(t, err)
+
+	dump, err = yaml.Marshal(stored.Spec())
+	require.NoError(t, err)
+
+	assert.Contains(t, string(dump), "keyID: key-2")
+
+	// previous key is not needed anymore
+	writeKeys(t, keysPath, "key-2", "key-2")
+	require.NoError(t, keys.Reload())
+
+	list, err := st.List(ctx, ns.Metadata())
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "top secret", list.Items[0].(*meta.Namespace).TypedSpec().Description)
+
+	// unencrypted resources of sensitive types are still readable, and get encrypted on reencryption
+	r, err = st.Get(ctx, conformance.NewPathResource("default", "/var").Metadata())
+	require.NoError(t, err)
+	assert.IsType(t, &conformance.PathResource{}, r)
+
+	count, err = encrypted.NewState(core, sensitiveTypes(t), keys).Reencrypt(ctx, conformance.NewPathResource("default", "").Metadata())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	stored, err = core.Get(ctx, conformance.NewPathResource("default", "/var").Metadata())
+	require.NoError(t, err)
+	assert.IsType(t, &resource.Any{}, stored)
+}
+
+func TestWatchKeyRemoved(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys, keysPath := newKeyProvider(t)
+
+	core := namespaced.NewState(inmem.Build)
+	st := state.WrapCore(encrypted.NewState(core, sensitiveTypes(t), keys))
+
+	ch := make(chan state.Event)
+
+	require.NoError(t, st.WatchKind(ctx, conformance.NewPathResource("default", "").Metadata(), ch))
+
+	require.NoError(t, st.Create(ctx, conformance.NewPathResource("default", "/var")))
+
+	event := <-ch
+	assert.Equal(t, state.Created, event.Type)
+	assert.IsType(t, &conformance.PathResource{}, event.Resource)
+
+	// key of the stored resource is removed mid-watch
+	writeKeys(t, keysPath, "key-2", "key-2")
+	require.NoError(t, keys.Reload())
+
+	stored, err := core.Get(ctx, conformance.NewPathResource("default", "/var").Metadata())
+	require.NoError(t, err)
+
+	updated := stored.DeepCopy()
+	updated.Metadata().BumpVersion()
+
+	require.NoError(t, core.Update(ctx, stored.Metadata().Version(), updated))
+
+	// update which can't be decrypted is reported, and the watch keeps going
+	event = <-ch
+	assert.Equal(t, state.Errored, event.Type)
+	assert.Equal(t, "/var", event.Resource.Metadata().ID())
+	assert.Equal(t, updated.Metadata().Version(), event.Resource.Metadata().Version())
+	assert.Error(t, event.Error)
+
+	_, err = st.WatchFor(ctx, conformance.NewPathResource("default", "/var").Metadata(), state.WithEventTypes(state.Updated))
+	assert.Error(t, err)
+
+	require.NoError(t, st.Create(ctx, conformance.NewPathResource("default", "/etc")))
+
+	event = <-ch
+	assert.Equal(t, state.Created, event.Type)
+	assert.Equal(t, "/etc", event.Resource.Metadata().ID())
+	assert.IsType(t, &conformance.PathResource{}, event.Resource)
+
+	require.NoError(t, core.Destroy(ctx, stored.Metadata()))
+
+	event = <-ch
+	assert.Equal(t, state.Destroyed, event.Type)
+	assert.Equal(t, "/var", event.Resource.Metadata().ID())
+	assert.IsType(t, &resource.Tombstone{}, event.Resource)
+}
+
+func TestNonSensitiveEnvelope(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	keys, _ := newKeyProvider(t)
+
+	core := namespaced.NewState(inmem.Build)
+	st := state.WrapCore(encrypted.NewState(core, sensitiveTypes(t), keys))
+
+	// spec of a resource type which is not sensitive looks like an envelope, but it's not decrypted
+	md := resource.NewMetadata("default", "Plain.test.cosi.dev", "a", resource.VersionUndefined)
+	md.BumpVersion()
+
+	plain := &resource.Any{}
+	*plain.Metadata() = md
+
+	require.NoError(t, plain.UnmarshalSpecYAML([]byte("encryption:\n  algorithm: AES-256-GCM\n  keyID: key-1\n  nonce: AAAA\n  data: AAAA\n")))
+	require.NoError(t, core.Create(ctx, plain))
+
+	r, err := st.Get(ctx, md)
+	require.NoError(t, err)
+	assert.Equal(t, plain.Spec(), r.Spec())
+}
+
+func TestFileKeyProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for _, tt := range []struct {
+		name          string
+		contents      string
+		expectedError string
+	}{
+		{
+			name:          "size",
+			contents:      "primary: a\nkeys:\n  - id: a\n    secret: YWJj\n",
+			expectedError: "key \"a\" has size 3, expected 32",
+		},
+		{
+			name:          "primary",
+			contents:      "primary: b\nkeys: []\n",
+			expectedError: "primary key \"b\" is not declared",
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".yaml")
+
+			require.NoError(t, os.WriteFile(path, []byte(tt.contents), 0o600))
+
+			_, err := encrypted.NewFileKeyProvider(path)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encrypted
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeySize is the size of the encryption key (AES-256).
+const KeySize = 32
+
+// Key is an encryption key.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// KeyProvider provides encryption keys.
+//
+// Keys are rotated by changing the primary key while keeping the previous keys
+// available for decryption until all the resources are re-encrypted.
+type KeyProvider interface {
+	// PrimaryKey returns the key new writes are encrypted with.
+	PrimaryKey(ctx context.Context) (Key, error)
+	// Key returns the key by ID.
+	Key(ctx context.Context, id string) (Key, error)
+}
+
+// FileKeyProvider reads keys from a YAML file.
+//
+// File format:
+//
+//	primary: key-2
+//	keys:
+//	  - id: key-1
+//	    secret: <base64 encoded 32 bytes>
+//	  - id: key-2
+//	    secret: <base64 encoded 32 bytes>
+//
+// The file is read again if the key is not found, so that keys can be rotated without a restart.
+type FileKeyProvider struct {
+	path string
+
+	mu      sync.Mutex
+	primary string
+	keys    map[string]Key
+}
+
+// NewFileKeyProvider creates new FileKeyProvider.
+func NewFileKeyProvider(path string) (*FileKeyProvider, error) {
+	provider := &FileKeyProvider{
+		path: path,
+	}
+
+	if err := provider.Reload(); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+type keyFile struct {
+	Primary string `yaml:"primary"`
+	Keys    []struct {
+		ID     string `yaml:"id"`
+		Secret string `yaml:"secret"`
+	} `yaml:"keys"`
+}
+
+// Reload the keys from the file.
+func (provider *FileKeyProvider) Reload() error {
+	data, err := os.ReadFile(provider.path)
+	if err != nil {
+		return fmt.Errorf("error reading keys: %w", err)
+	}
+
+	var file keyFile
+
+	if err = yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("error decoding keys file %q: %w", provider.path, err)
+	}
+
+	keys := make(map[string]Key, len(file.Keys))
+
+	for _, k := range file.Keys {
+		secret, err := base64.StdEncoding.DecodeString(k.Secret)
+		if err != nil {
+			return fmt.Errorf("error decoding key %q: %w", k.ID, err)
+		}
+
+		if len(secret) != KeySize {
+			return fmt.Errorf("key %q has size %d, expected %d", k.ID, len(secret), KeySize)
+		}
+
+		if _, exists := keys[k.ID]; exists {
+			return fmt.Errorf("key %q is declared more than once", k.ID)
+		}
+
+		keys[k.ID] = Key{
+			ID:     k.ID,
+			Secret: secret,
+		}
+	}
+
+	if _, ok := keys[file.Primary]; !ok {
+		return fmt.Errorf("primary key %q is not declared", file.Primary)
+	}
+
+	provider.mu.Lock()
+	provider.primary = file.Primary
+	provider.keys = keys
+	provider.mu.Unlock()
+
+	return nil
+}
+
+// PrimaryKey implements KeyProvider.
+func (provider *FileKeyProvider) PrimaryKey(ctx context.Context) (Key, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	return provider.keys[provider.primary], nil
+}
+
+// Key implements KeyProvider.
+func (provider *FileKeyProvider) Key(ctx context.Context, id string) (Key, error) {
+	provider.mu.Lock()
+	key, ok := provider.keys[id]
+	provider.mu.Unlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if err := provider.Reload(); err != nil {
+		return Key{}, err
+	}
+
+	provider.mu.Lock()
+	key, ok = provider.keys[id]
+	provider.mu.Unlock()
+
+	if !ok {
+		return Key{}, fmt.Errorf("key %q is not found", id)
+	}
+
+	return key, nil
+}
@@ -73,6 +73,14 @@ func (collection *ResourceCollection) Get(resourceID resource.ID) (resource.Reso
 	return res.DeepCopy(), nil
 }
 
+// Count returns the number of resources in the collection.
+func (collection *ResourceCollection) Count() int {
+	collection.mu.Lock()
+	defer collection.mu.Unlock()
+
+	return len(collection.storage)
+}
+
 // List resources.
 func (collection *ResourceCollection) List() (resource.List, error) {
 	collection.mu.Lock()
@@ -116,7 +124,13 @@ func (collection *ResourceCollection) Create(resource resource.Resource) error {
 }
 
 // Update a resource.
-func (collection *ResourceCollection) Update(curVersion resource.Version, newResource resource.Resource) error {
+func (collection *ResourceCollection) Update(ctx context.Context, curVersion resource.Version, newResource resource.Resource, opts ...state.UpdateOption) error {
+	var options state.UpdateOptions
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	newResource = newResource.DeepCopy()
 	id := newResource.Metadata().ID()
 
@@ -136,6 +150,18 @@ func (collection *ResourceCollection) Update(curVersion resource.Version, newRes
 		return ErrVersionConflict(curResource.Metadata(), curVersion, curResource.Metadata().Version())
 	}
 
+	if !options.ForcePhaseTransition {
+		if err := resource.ValidatePhaseTransition(curResource.Metadata().Phase(), newResource.Metadata().Phase()); err != nil {
+			return ErrPhaseTransition(curResource.Metadata(), err)
+		}
+	}
+
+	if !options.ForceFinalizerRemoval {
+		if err := state.CheckFinalizerOwnership(state.OwnerFromContext(ctx), curResource.Metadata(), newResource.Metadata()); err != nil {
+			return ErrPermissionDenied(curResource.Metadata(), err)
+		}
+	}
+
 	collection.storage[id] = newResource
 
 	collection.publish(state.Event{
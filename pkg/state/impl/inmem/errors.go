@@ -5,54 +5,41 @@
 package inmem
 
 import (
-	"fmt"
-
 	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/state"
 )
 
-type eNotFound struct {
-	error
-}
-
-func (eNotFound) NotFoundError() {}
-
 // ErrNotFound generates error compatible with state.ErrNotFound.
 func ErrNotFound(r resource.Pointer) error {
-	return eNotFound{
-		fmt.Errorf("resource %s doesn't exist", r),
-	}
-}
-
-type eConflict struct {
-	error
+	return state.NewNotFoundError(r)
 }
 
-func (eConflict) ConflictError() {}
-
 // ErrAlreadyExists generates error compatible with state.ErrConflict.
 func ErrAlreadyExists(r resource.Reference) error {
-	return eConflict{
-		fmt.Errorf("resource %s already exists", r),
-	}
+	return state.NewAlreadyExistsError(r)
 }
 
 // ErrVersionConflict generates error compatible with state.ErrConflict.
 func ErrVersionConflict(r resource.Reference, expected, found resource.Version) error {
-	return eConflict{
-		fmt.Errorf("resource %s update conflict: expected version %q, actual version %q", r, expected, found),
-	}
+	return state.NewVersionConflictError(r, expected, found)
 }
 
 // ErrUpdateSameVersion generates error compatible with state.ErrConflict.
 func ErrUpdateSameVersion(r resource.Reference, version resource.Version) error {
-	return eConflict{
-		fmt.Errorf("resource %s update conflict: same %q version for new and existing objects", r, version),
-	}
+	return state.NewUpdateSameVersionError(r, version)
 }
 
 // ErrPendingFinalizers generates error compatible with state.ErrConflict.
 func ErrPendingFinalizers(r resource.Metadata) error {
-	return eConflict{
-		fmt.Errorf("resource %s has pending finalizers %s", r, r.Finalizers()),
-	}
+	return state.NewPendingFinalizersError(r)
+}
+
+// ErrPhaseTransition generates error compatible with state.ErrPhaseTransition.
+func ErrPhaseTransition(r resource.Reference, err error) error {
+	return state.NewPhaseTransitionError(r, err)
+}
+
+// ErrPermissionDenied generates error compatible with state.ErrPermissionDenied.
+func ErrPermissionDenied(r resource.Reference, err error) error {
+	return state.NewPermissionDeniedError(r, err)
 }
@@ -7,6 +7,7 @@ package inmem
 
 import (
 	"context"
+	"sort"
 	"sync"
 
 	"github.com/talos-systems/os-runtime/pkg/resource"
@@ -55,7 +56,7 @@ func (state *State) Create(ctx context.Context, resource resource.Resource, opts
 
 // Update a resource.
 func (state *State) Update(ctx context.Context, curVersion resource.Version, newResource resource.Resource, opts ...state.UpdateOption) error {
-	return state.getCollection(newResource.Metadata().Type()).Update(curVersion, newResource)
+	return state.getCollection(newResource.Metadata().Type()).Update(ctx, curVersion, newResource, opts...)
 }
 
 // Destroy a resource.
@@ -72,3 +73,34 @@ func (state *State) Watch(ctx context.Context, resourcePointer resource.Pointer,
 func (state *State) WatchKind(ctx context.Context, resourceKind resource.Kind, ch chan<- state.Event, opts ...state.WatchKindOption) error {
 	return state.getCollection(resourceKind.Type()).WatchAll(ctx, ch, opts...)
 }
+
+// Discover implements state.Discovery.
+func (st *State) Discover(ctx context.Context) ([]state.NamespaceSummary, error) {
+	var types []state.TypeSummary
+
+	st.collections.Range(func(key, value interface{}) bool {
+		if count := value.(*ResourceCollection).Count(); count > 0 {
+			types = append(types, state.TypeSummary{
+				Type:  key.(resource.Type),
+				Count: count,
+			})
+		}
+
+		return true
+	})
+
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Type < types[j].Type
+	})
+
+	return []state.NamespaceSummary{
+		{
+			Namespace: st.ns,
+			Types:     types,
+		},
+	}, nil
+}
@@ -20,6 +20,7 @@ func TestInterfaces(t *testing.T) {
 	t.Parallel()
 
 	assert.Implements(t, (*state.CoreState)(nil), new(inmem.State))
+	assert.Implements(t, (*state.Discovery)(nil), new(inmem.State))
 }
 
 func TestLocalConformance(t *testing.T) {
@@ -0,0 +1,403 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package kv provides an implementation of state.State on top of a key-value Store.
+//
+// State implements all the resource semantics (versioning, phases, finalizers, watches),
+// so that a new storage engine only needs to implement the Store interface.
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/talos-systems/os-runtime/api/v1alpha1"
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/protobuf"
+	"github.com/talos-systems/os-runtime/pkg/state"
+)
+
+// Decoder converts resources read from the Store.
+//
+// Resources are read from the Store as resource.Any.
+type Decoder func(*resource.Any) (resource.Resource, error)
+
+// Options configure the State.
+type Options struct {
+	KeyPrefix string
+	Decoder   Decoder
+}
+
+// Option builds Options.
+type Option func(*Options)
+
+// WithKeyPrefix sets the prefix for all the keys, so that several states can share the Store.
+func WithKeyPrefix(prefix string) Option {
+	return func(opts *Options) {
+		opts.KeyPrefix = prefix
+	}
+}
+
+// WithDecoder sets the Decoder, e.g. registry.TypeRegistry.FromAny.
+func WithDecoder(decoder Decoder) Option {
+	return func(opts *Options) {
+		opts.Decoder = decoder
+	}
+}
+
+// State implements state.CoreState on top of the Store.
+type State struct {
+	store   Store
+	options Options
+}
+
+// NewState creates new State.
+func NewState(store Store, opts ...Option) *State {
+	options := Options{
+		Decoder: func(any *resource.Any) (resource.Resource, error) {
+			return any, nil
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &State{
+		store:   store,
+		options: options,
+	}
+}
+
+func (st *State) kindPrefix(kind resource.Kind) string {
+	return st.options.KeyPrefix + url.PathEscape(kind.Namespace()) + "/" + url.PathEscape(kind.Type()) + "/"
+}
+
+func (st *State) key(ptr resource.Pointer) string {
+	return st.kindPrefix(ptr) + url.PathEscape(ptr.ID())
+}
+
+func (st *State) encode(r resource.Resource) ([]byte, error) {
+	protoResource, err := protobuf.ResourceToProto(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(protoResource)
+}
+
+func (st *State) decode(data []byte) (resource.Resource, error) {
+	var protoResource v1alpha1.Resource
+
+	if err := proto.Unmarshal(data, &protoResource); err != nil {
+		return nil, fmt.Errorf("error decoding resource: %w", err)
+	}
+
+	any, err := resource.NewAnyFromProto(protoResource.GetMetadata(), protoResource.GetSpec())
+	if err != nil {
+		return nil, fmt.Errorf("error decoding resource: %w", err)
+	}
+
+	return st.options.Decoder(any)
+}
+
+// Get a resource.
+func (st *State) Get(ctx context.Context, resourcePointer resource.Pointer, opts ...state.GetOption) (resource.Resource, error) {
+	kv, ok, err := st.store.Get(ctx, st.key(resourcePointer))
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, state.NewNotFoundError(resourcePointer)
+	}
+
+	return st.decode(kv.Value)
+}
+
+// List resources.
+func (st *State) List(ctx context.Context, resourceKind resource.Kind, opts ...state.ListOption) (resource.List, error) {
+	kvs, _, err := st.store.List(ctx, st.kindPrefix(resourceKind))
+	if err != nil {
+		return resource.List{}, err
+	}
+
+	result := resource.List{
+		Items: make([]resource.Resource, 0, len(kvs)),
+	}
+
+	for _, kv := range kvs {
+		r, err := st.decode(kv.Value)
+		if err != nil {
+			return resource.List{}, err
+		}
+
+		result.Items = append(result.Items, r)
+	}
+
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].Metadata().ID() < result.Items[j].Metadata().ID()
+	})
+
+	return result, nil
+}
+
+// Create a resource.
+func (st *State) Create(ctx context.Context, r resource.Resource, opts ...state.CreateOption) error {
+	value, err := st.encode(r)
+	if err != nil {
+		return err
+	}
+
+	_, err = st.store.CompareAndSwap(ctx, st.key(r.Metadata()), 0, value)
+	if errors.Is(err, ErrCompareFailed) {
+		return state.NewAlreadyExistsError(r.Metadata())
+	}
+
+	return err
+}
+
+// Update a resource.
+func (st *State) Update(ctx context.Context, curVersion resource.Version, newResource resource.Resource, opts ...state.UpdateOption) error {
+	var options state.UpdateOptions
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	key := st.key(newResource.Metadata())
+
+	value, err := st.encode(newResource)
+	if err != nil {
+		return err
+	}
+
+	for {
+		kv, ok, err := st.store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return state.NewNotFoundError(newResource.Metadata())
+		}
+
+		curResource, err := st.decode(kv.Value)
+		if err != nil {
+			return err
+		}
+
+		if newResource.Metadata().Version().Equal(curVersion) {
+			return state.NewUpdateSameVersionError(curResource.Metadata(), curVersion)
+		}
+
+		if !curResource.Metadata().Version().Equal(curVersion) {
+			return state.NewVersionConflictError(curResource.Metadata(), curVersion, curResource.Metadata().Version())
+		}
+
+		if !options.ForcePhaseTransition {
+			if err = resource.ValidatePhaseTransition(curResource.Metadata().Phase(), newResource.Metadata().Phase()); err != nil {
+				return state.NewPhaseTransitionError(curResource.Metadata(), err)
+			}
+		}
+
+		if !options.ForceFinalizerRemoval {
+			if err = state.CheckFinalizerOwnership(state.OwnerFromContext(ctx), curResource.Metadata(), newResource.Metadata()); err != nil {
+				return state.NewPermissionDeniedError(curResource.Metadata(), err)
+			}
+		}
+
+		_, err = st.store.CompareAndSwap(ctx, key, kv.Revision, value)
+		if errors.Is(err, ErrCompareFailed) {
+			// the key was modified concurrently, the checks above should be redone
+			continue
+		}
+
+		return err
+	}
+}
+
+// Destroy a resource.
+func (st *State) Destroy(ctx context.Context, resourcePointer resource.Pointer, opts ...state.DestroyOption) error {
+	key := st.key(resourcePointer)
+
+	for {
+		kv, ok, err := st.store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return state.NewNotFoundError(resourcePointer)
+		}
+
+		curResource, err := st.decode(kv.Value)
+		if err != nil {
+			return err
+		}
+
+		if !curResource.Metadata().Finalizers().Empty() {
+			return state.NewPendingFinalizersError(*curResource.Metadata())
+		}
+
+		err = st.store.CompareAndDelete(ctx, key, kv.Revision)
+		if errors.Is(err, ErrCompareFailed) {
+			continue
+		}
+
+		return err
+	}
+}
+
+// Watch a resource.
+func (st *State) Watch(ctx context.Context, resourcePointer resource.Pointer, ch chan<- state.Event, opts ...state.WatchOption) error {
+	key := st.key(resourcePointer)
+
+	// list is used instead of get to capture the revision
+	kvs, revision, err := st.store.List(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	initial := state.Event{
+		Type:     state.Destroyed,
+		Resource: resource.NewTombstone(resource.NewMetadata(resourcePointer.Namespace(), resourcePointer.Type(), resourcePointer.ID(), resource.VersionUndefined)),
+	}
+
+	for _, kv := range kvs {
+		if kv.Key != key {
+			continue
+		}
+
+		initial.Type = state.Created
+
+		if initial.Resource, err = st.decode(kv.Value); err != nil {
+			return err
+		}
+	}
+
+	return st.watch(ctx, key, revision, ch, []state.Event{initial}, func(change Change) bool {
+		return change.Key == key
+	})
+}
+
+// WatchKind all resources by type.
+func (st *State) WatchKind(ctx context.Context, resourceKind resource.Kind, ch chan<- state.Event, opts ...state.WatchKindOption) error {
+	var options state.WatchKindOptions
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	prefix := st.kindPrefix(resourceKind)
+
+	kvs, revision, err := st.store.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	var bootstrap []state.Event
+
+	if options.BootstrapContents {
+		list := resource.List{
+			Items: make([]resource.Resource, 0, len(kvs)),
+		}
+
+		for _, kv := range kvs {
+			r, err := st.decode(kv.Value)
+			if err != nil {
+				return err
+			}
+
+			list.Items = append(list.Items, r)
+		}
+
+		sort.Slice(list.Items, func(i, j int) bool {
+			return list.Items[i].Metadata().ID() < list.Items[j].Metadata().ID()
+		})
+
+		for _, r := range list.Items {
+			bootstrap = append(bootstrap, state.Event{
+				Type:     state.Created,
+				Resource: r,
+			})
+		}
+	}
+
+	return st.watch(ctx, prefix, revision, ch, bootstrap, func(Change) bool {
+		return true
+	})
+}
+
+func (st *State) watch(ctx context.Context, prefix string, revision uint64, ch chan<- state.Event, initial []state.Event, filter func(Change) bool) error {
+	changes := make(chan Change)
+
+	if err := st.store.Watch(ctx, prefix, revision, changes); err != nil {
+		return err
+	}
+
+	go func() {
+		for _, event := range initial {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- event:
+			}
+		}
+
+		for {
+			var change Change
+
+			select {
+			case <-ctx.Done():
+				return
+			case change = <-changes:
+			}
+
+			if !filter(change) {
+				continue
+			}
+
+			event, err := st.changeToEvent(change)
+			if err != nil {
+				// there's no way to signal error in this case, so for now just return
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- event:
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (st *State) changeToEvent(change Change) (state.Event, error) {
+	var (
+		event state.Event
+		err   error
+	)
+
+	switch {
+	case change.Type == Delete:
+		event.Type = state.Destroyed
+		event.Resource, err = st.decode(change.PrevValue)
+	case change.PrevValue == nil:
+		event.Type = state.Created
+		event.Resource, err = st.decode(change.Value)
+	default:
+		event.Type = state.Updated
+		event.Resource, err = st.decode(change.Value)
+	}
+
+	return event, err
+}
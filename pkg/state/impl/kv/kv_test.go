@@ -0,0 +1,109 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package kv_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/conformance"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/kv"
+	"github.com/talos-systems/os-runtime/pkg/state/registry"
+)
+
+func TestInterfaces(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*state.CoreState)(nil), new(kv.State))
+	assert.Implements(t, (*kv.Store)(nil), new(kv.MemoryStore))
+}
+
+func newState(t *testing.T, store kv.Store) state.State {
+	types := registry.NewTypeRegistry()
+
+	require.NoError(t, types.Register(conformance.PathExtension{}.ResourceDefinition(), registry.FactoryFor(conformance.NewPathResource("", ""))))
+
+	return state.WrapCore(kv.NewState(store, kv.WithKeyPrefix("/state/"), kv.WithDecoder(types.FromAny)))
+}
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, &conformance.StateSuite{
+		State:      newState(t, kv.NewMemoryStore()),
+		Namespaces: []resource.Namespace{"default", "controller", "system", "runtime"},
+	})
+}
+
+func TestUndecodedResources(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := kv.NewMemoryStore()
+
+	require.NoError(t, newState(t, store).Create(ctx, conformance.NewPathResource("default", "/var")))
+
+	// without the decoder resources are returned as resource.Any
+	r, err := kv.NewState(store, kv.WithKeyPrefix("/state/")).Get(ctx, conformance.NewPathResource("default", "/var").Metadata())
+	require.NoError(t, err)
+
+	assert.IsType(t, &resource.Any{}, r)
+	assert.Equal(t, "/var", r.Metadata().ID())
+}
+
+func TestMemoryStore(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	store := kv.NewMemoryStore(kv.WithHistoryCapacity(3))
+
+	rev1, err := store.CompareAndSwap(ctx, "a/1", 0, []byte("foo"))
+	require.NoError(t, err)
+
+	_, err = store.CompareAndSwap(ctx, "a/1", 0, []byte("bar"))
+	assert.True(t, errors.Is(err, kv.ErrCompareFailed))
+
+	rev2, err := store.CompareAndSwap(ctx, "a/1", rev1, []byte("bar"))
+	require.NoError(t, err)
+	assert.Greater(t, rev2, rev1)
+
+	_, err = store.CompareAndSwap(ctx, "b/1", 0, []byte("baz"))
+	require.NoError(t, err)
+
+	kvs, revision, err := store.List(ctx, "a/")
+	require.NoError(t, err)
+	assert.Equal(t, []kv.KeyValue{{Key: "a/1", Value: []byte("bar"), Revision: rev2}}, kvs)
+
+	ch := make(chan kv.Change)
+
+	require.NoError(t, store.Watch(ctx, "a/", rev1, ch))
+
+	assert.Equal(t, kv.Change{Type: kv.Put, Key: "a/1", Value: []byte("bar"), PrevValue: []byte("foo"), Revision: rev2}, <-ch)
+
+	assert.True(t, errors.Is(store.CompareAndDelete(ctx, "a/1", rev1), kv.ErrCompareFailed))
+	require.NoError(t, store.CompareAndDelete(ctx, "a/1", rev2))
+
+	assert.Equal(t, kv.Change{Type: kv.Delete, Key: "a/1", PrevValue: []byte("bar"), Revision: revision + 1}, <-ch)
+
+	_, ok, err := store.Get(ctx, "a/1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = store.CompareAndSwap(ctx, "a/2", 0, []byte("foo"))
+	require.NoError(t, err)
+
+	// first revisions are not in the history anymore
+	assert.True(t, errors.Is(store.Watch(ctx, "a/", 0, ch), kv.ErrCompacted))
+}
@@ -0,0 +1,221 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package kv
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryStoreOptions configure the MemoryStore.
+type MemoryStoreOptions struct {
+	HistoryCapacity int
+}
+
+// MemoryStoreOption builds MemoryStoreOptions.
+type MemoryStoreOption func(*MemoryStoreOptions)
+
+// WithHistoryCapacity sets the number of changes kept for the change feed.
+func WithHistoryCapacity(capacity int) MemoryStoreOption {
+	return func(opts *MemoryStoreOptions) {
+		opts.HistoryCapacity = capacity
+	}
+}
+
+// MemoryStore implements Store in memory.
+type MemoryStore struct {
+	mu       sync.Mutex
+	data     map[string]KeyValue
+	revision uint64
+
+	history  []Change
+	capacity int
+
+	// closed on every change
+	changed chan struct{}
+}
+
+// NewMemoryStore creates new MemoryStore.
+func NewMemoryStore(opts ...MemoryStoreOption) *MemoryStore {
+	options := MemoryStoreOptions{
+		HistoryCapacity: 1000,
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &MemoryStore{
+		data:     map[string]KeyValue{},
+		capacity: options.HistoryCapacity,
+		changed:  make(chan struct{}),
+	}
+}
+
+// Get implements Store.
+func (store *MemoryStore) Get(ctx context.Context, key string) (KeyValue, bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	kv, ok := store.data[key]
+
+	return kv, ok, nil
+}
+
+// List implements Store.
+func (store *MemoryStore) List(ctx context.Context, prefix string) ([]KeyValue, uint64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var kvs []KeyValue
+
+	for key, kv := range store.data {
+		if strings.HasPrefix(key, prefix) {
+			kvs = append(kvs, kv)
+		}
+	}
+
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].Key < kvs[j].Key
+	})
+
+	return kvs, store.revision, nil
+}
+
+// CompareAndSwap implements Store.
+func (store *MemoryStore) CompareAndSwap(ctx context.Context, key string, revision uint64, value []byte) (uint64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	current, ok := store.data[key]
+
+	if current.Revision != revision {
+		return 0, fmt.Errorf("error updating key %q: %w", key, ErrCompareFailed)
+	}
+
+	store.revision++
+
+	store.data[key] = KeyValue{
+		Key:      key,
+		Value:    append([]byte(nil), value...),
+		Revision: store.revision,
+	}
+
+	change := Change{
+		Type:     Put,
+		Key:      key,
+		Value:    store.data[key].Value,
+		Revision: store.revision,
+	}
+
+	if ok {
+		change.PrevValue = current.Value
+	}
+
+	store.publish(change)
+
+	return store.revision, nil
+}
+
+// CompareAndDelete implements Store.
+func (store *MemoryStore) CompareAndDelete(ctx context.Context, key string, revision uint64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	current, ok := store.data[key]
+
+	if !ok || current.Revision != revision {
+		return fmt.Errorf("error deleting key %q: %w", key, ErrCompareFailed)
+	}
+
+	store.revision++
+
+	delete(store.data, key)
+
+	store.publish(Change{
+		Type:      Delete,
+		Key:       key,
+		PrevValue: current.Value,
+		Revision:  store.revision,
+	})
+
+	return nil
+}
+
+func (store *MemoryStore) publish(change Change) {
+	store.history = append(store.history, change)
+
+	if len(store.history) > store.capacity {
+		store.history = append([]Change(nil), store.history[len(store.history)-store.capacity:]...)
+	}
+
+	close(store.changed)
+	store.changed = make(chan struct{})
+}
+
+// changesAfter returns the changes after the revision, and the channel which is closed on the next change.
+func (store *MemoryStore) changesAfter(revision uint64) ([]Change, <-chan struct{}, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if revision >= store.revision {
+		return nil, store.changed, nil
+	}
+
+	// history starts with the change right after the revision
+	first := store.revision - uint64(len(store.history)) + 1
+
+	if revision+1 < first {
+		return nil, nil, fmt.Errorf("error watching from revision %d: %w", revision, ErrCompacted)
+	}
+
+	return append([]Change(nil), store.history[revision+1-first:]...), store.changed, nil
+}
+
+// Watch implements Store.
+func (store *MemoryStore) Watch(ctx context.Context, prefix string, revision uint64, ch chan<- Change) error {
+	if _, _, err := store.changesAfter(revision); err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			changes, changed, err := store.changesAfter(revision)
+			if err != nil {
+				// watcher fell behind the history, there's no way to signal error in this case
+				return
+			}
+
+			for _, change := range changes {
+				revision = change.Revision
+
+				if !strings.HasPrefix(change.Key, prefix) {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- change:
+				}
+			}
+
+			if len(changes) > 0 {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+			}
+		}
+	}()
+
+	return nil
+}
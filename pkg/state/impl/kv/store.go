@@ -0,0 +1,84 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package kv
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrCompareFailed is returned by the Store when the revision of the key doesn't match the expected one.
+	ErrCompareFailed = errors.New("revision doesn't match")
+
+	// ErrCompacted is returned by the Store when the requested revision is not available in the change feed.
+	ErrCompacted = errors.New("revision was compacted")
+)
+
+// KeyValue is a key with its value.
+type KeyValue struct {
+	Key   string
+	Value []byte
+
+	// Revision of the last modification of the key.
+	Revision uint64
+}
+
+// ChangeType is a type of the Change.
+type ChangeType int
+
+// Change types.
+const (
+	Put ChangeType = iota
+	Delete
+)
+
+// Change is an entry of the Store change feed.
+type Change struct {
+	Type ChangeType
+	Key  string
+
+	// Value is the new value of the key, empty for Delete.
+	Value []byte
+
+	// PrevValue is the value before the change, empty if the key was created.
+	PrevValue []byte
+
+	// Revision of the Store after the change.
+	Revision uint64
+}
+
+// Store is an ordered key-value storage with compare-and-swap and a change feed.
+//
+// Each modification of the Store increases the Store revision.
+// Revision 0 is never assigned to a key, it's used to denote a key which doesn't exist.
+type Store interface {
+	// Get the key.
+	//
+	// If the key doesn't exist, ok is false.
+	Get(ctx context.Context, key string) (kv KeyValue, ok bool, err error)
+
+	// List the keys with the prefix ordered by key.
+	//
+	// List returns the Store revision the list was taken at.
+	List(ctx context.Context, prefix string) (kvs []KeyValue, revision uint64, err error)
+
+	// CompareAndSwap sets the value of the key if the key revision matches.
+	//
+	// Revision 0 expects the key not to exist.
+	// If the revision doesn't match, ErrCompareFailed is returned.
+	CompareAndSwap(ctx context.Context, key string, revision uint64, value []byte) (newRevision uint64, err error)
+
+	// CompareAndDelete deletes the key if the key revision matches.
+	//
+	// If the revision doesn't match, ErrCompareFailed is returned.
+	CompareAndDelete(ctx context.Context, key string, revision uint64) error
+
+	// Watch sends changes of the keys with the prefix which happened after the revision.
+	//
+	// Watch is canceled when context gets canceled.
+	// If the revision is not available anymore, ErrCompacted is returned.
+	Watch(ctx context.Context, prefix string, revision uint64, ch chan<- Change) error
+}
@@ -7,6 +7,8 @@ package namespaced
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/talos-systems/os-runtime/pkg/resource"
@@ -20,6 +22,10 @@ type StateBuilder func(resource.Namespace) state.CoreState
 type State struct {
 	namespaces sync.Map
 
+	// creates hold the read lock, so that closing the namespace waits for in-flight creates
+	closedMu sync.RWMutex
+	closed   map[resource.Namespace]struct{}
+
 	builder StateBuilder
 }
 
@@ -27,6 +33,7 @@ type State struct {
 func NewState(builder StateBuilder) *State {
 	return &State{
 		builder: builder,
+		closed:  map[resource.Namespace]struct{}{},
 	}
 }
 
@@ -54,8 +61,15 @@ func (st *State) List(ctx context.Context, kind resource.Kind, opts ...state.Lis
 
 // Create a resource.
 //
-// If a resource already exists, Create returns an error.
+// If a resource already exists, or the namespace is closed, Create returns an error.
 func (st *State) Create(ctx context.Context, res resource.Resource, opts ...state.CreateOption) error {
+	st.closedMu.RLock()
+	defer st.closedMu.RUnlock()
+
+	if _, closed := st.closed[res.Metadata().Namespace()]; closed {
+		return state.NewNamespaceClosedError(res.Metadata())
+	}
+
 	return st.getNamespace(res.Metadata().Namespace()).Create(ctx, res, opts...)
 }
 
@@ -89,3 +103,70 @@ func (st *State) Watch(ctx context.Context, ptr resource.Pointer, ch chan<- stat
 func (st *State) WatchKind(ctx context.Context, kind resource.Kind, ch chan<- state.Event, opts ...state.WatchKindOption) error {
 	return st.getNamespace(kind.Namespace()).WatchKind(ctx, kind, ch, opts...)
 }
+
+// CloseNamespace rejects creating resources in the namespace until it's opened again.
+//
+// Creates which are in progress are finished before CloseNamespace returns.
+func (st *State) CloseNamespace(ns resource.Namespace) {
+	st.closedMu.Lock()
+	defer st.closedMu.Unlock()
+
+	st.closed[ns] = struct{}{}
+}
+
+// OpenNamespace allows creating resources in the namespace closed with CloseNamespace.
+func (st *State) OpenNamespace(ns resource.Namespace) {
+	st.closedMu.Lock()
+	defer st.closedMu.Unlock()
+
+	delete(st.closed, ns)
+}
+
+// DropNamespace removes the backend of the namespace freeing the resources.
+//
+// Namespace backend is rebuilt on next access. Watches established on the dropped
+// backend don't receive any further events. Resources left in the namespace are lost
+// without running their finalizers, so the namespace should be emptied first.
+func (st *State) DropNamespace(ns resource.Namespace) {
+	st.namespaces.Delete(ns)
+}
+
+// Discover implements state.Discovery.
+//
+// Namespace backends should implement state.Discovery.
+func (st *State) Discover(ctx context.Context) ([]state.NamespaceSummary, error) {
+	var (
+		result []state.NamespaceSummary
+		err    error
+	)
+
+	st.namespaces.Range(func(key, value interface{}) bool {
+		discovery, ok := value.(state.Discovery)
+		if !ok {
+			err = fmt.Errorf("backend of namespace %q doesn't support discovery", key)
+
+			return false
+		}
+
+		var summaries []state.NamespaceSummary
+
+		summaries, err = discovery.Discover(ctx)
+		if err != nil {
+			return false
+		}
+
+		result = append(result, summaries...)
+
+		return true
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace < result[j].Namespace
+	})
+
+	return result, nil
+}
@@ -5,9 +5,11 @@
 package namespaced_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/talos-systems/os-runtime/pkg/resource"
@@ -21,6 +23,7 @@ func TestInterfaces(t *testing.T) {
 	t.Parallel()
 
 	assert.Implements(t, (*state.CoreState)(nil), new(namespaced.State))
+	assert.Implements(t, (*state.Discovery)(nil), new(namespaced.State))
 }
 
 func TestNamespacedConformance(t *testing.T) {
@@ -31,3 +34,83 @@ func TestNamespacedConformance(t *testing.T) {
 		Namespaces: []resource.Namespace{"default", "controller", "system", "runtime"},
 	})
 }
+
+func TestDropNamespace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	st := namespaced.NewState(inmem.Build)
+
+	path := conformance.NewPathResource("default", "a")
+
+	require.NoError(t, st.Create(ctx, path))
+	require.NoError(t, st.Create(ctx, conformance.NewPathResource("system", "a")))
+
+	st.DropNamespace("default")
+
+	_, err := st.Get(ctx, path.Metadata())
+	assert.True(t, state.IsNotFoundError(err))
+
+	_, err = st.Get(ctx, conformance.NewPathResource("system", "a").Metadata())
+	assert.NoError(t, err)
+
+	require.NoError(t, st.Create(ctx, path))
+}
+
+func TestCloseNamespace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	st := namespaced.NewState(inmem.Build)
+
+	path := conformance.NewPathResource("default", "a")
+
+	require.NoError(t, st.Create(ctx, path))
+
+	st.CloseNamespace("default")
+
+	err := st.Create(ctx, conformance.NewPathResource("default", "b"))
+	assert.True(t, state.IsConflictError(err))
+	assert.EqualError(t, err, `resource os/path(default/b@1) can't be created: namespace "default" is being torn down`)
+
+	// other namespaces and other operations are not affected
+	require.NoError(t, st.Create(ctx, conformance.NewPathResource("system", "b")))
+	require.NoError(t, st.Destroy(ctx, path.Metadata()))
+
+	st.OpenNamespace("default")
+
+	require.NoError(t, st.Create(ctx, conformance.NewPathResource("default", "b")))
+}
+
+func TestDiscover(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	st := state.WrapCore(namespaced.NewState(inmem.Build))
+
+	for _, path := range []*conformance.PathResource{
+		conformance.NewPathResource("system", "a"),
+		conformance.NewPathResource("default", "a"),
+		conformance.NewPathResource("default", "b"),
+	} {
+		require.NoError(t, st.Create(ctx, path))
+	}
+
+	// empty namespace
+	_, err := st.Get(ctx, conformance.NewPathResource("runtime", "a").Metadata())
+	require.True(t, state.IsNotFoundError(err))
+
+	summaries, err := st.(state.Discovery).Discover(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []state.NamespaceSummary{
+		{
+			Namespace: "default",
+			Types:     []state.TypeSummary{{Type: conformance.PathResourceType, Count: 2}},
+		},
+		{
+			Namespace: "system",
+			Types:     []state.TypeSummary{{Type: conformance.PathResourceType, Count: 1}},
+		},
+	}, summaries)
+}
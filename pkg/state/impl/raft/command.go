@@ -0,0 +1,70 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package raft
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/state"
+)
+
+// Op is the operation performed by the Command.
+type Op int
+
+// Operations supported by the Command.
+const (
+	OpCreate Op = iota
+	OpUpdate
+	OpDestroy
+)
+
+// Command is a write to the state replicated through the log.
+type Command struct {
+	Op Op
+
+	// Resource to create or update.
+	Resource resource.Resource
+
+	// Pointer to the resource to destroy.
+	Pointer resource.Metadata
+
+	CurVersion    resource.Version
+	UpdateOptions state.UpdateOptions
+
+	// Owner of the write, see state.WithOwner.
+	Owner string
+}
+
+// Entry is a log entry.
+//
+// Entries without a Command are no-ops appended by the leader.
+type Entry struct {
+	Term    uint64
+	Index   uint64
+	Command *Command
+}
+
+func (cmd *Command) apply(st state.CoreState) error {
+	ctx := context.Background()
+
+	if cmd.Owner != "" {
+		ctx = state.WithOwner(ctx, cmd.Owner)
+	}
+
+	switch cmd.Op {
+	case OpCreate:
+		return st.Create(ctx, cmd.Resource.DeepCopy())
+	case OpUpdate:
+		return st.Update(ctx, cmd.CurVersion, cmd.Resource.DeepCopy(), func(opts *state.UpdateOptions) {
+			*opts = cmd.UpdateOptions
+		})
+	case OpDestroy:
+		return st.Destroy(ctx, cmd.Pointer)
+	default:
+		return fmt.Errorf("unsupported operation %d", cmd.Op)
+	}
+}
@@ -0,0 +1,735 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package raft provides an implementation of state.State replicated across several nodes with the Raft consensus protocol.
+//
+// Writes are replicated through the log managed by the leader, and applied to the in-memory state of every node.
+// Reads and watches are served by the local state of any node.
+//
+// The log is kept in memory in full, snapshots and membership changes are not implemented.
+package raft
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/namespaced"
+)
+
+// NodeID identifies a node in the cluster.
+type NodeID string
+
+// Options configure the Node.
+type Options struct {
+	HeartbeatInterval time.Duration
+	ElectionTimeout   time.Duration
+}
+
+// Option builds Options.
+type Option func(*Options)
+
+// WithHeartbeatInterval sets the interval between the heartbeats sent by the leader.
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.HeartbeatInterval = interval
+	}
+}
+
+// WithElectionTimeout sets the base election timeout.
+//
+// Actual election timeout is randomized between the timeout and twice the timeout.
+func WithElectionTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.ElectionTimeout = timeout
+	}
+}
+
+// DefaultOptions returns default value of Options.
+func DefaultOptions() Options {
+	return Options{
+		HeartbeatInterval: 50 * time.Millisecond,
+		ElectionTimeout:   500 * time.Millisecond,
+	}
+}
+
+type role int
+
+const (
+	follower role = iota
+	candidate
+	leader
+)
+
+type proposal struct {
+	term uint64
+	ch   chan error
+}
+
+// Node is a member of the replicated state cluster.
+//
+// Node implements state.CoreState.
+type Node struct {
+	id        NodeID
+	peers     []NodeID
+	transport Transport
+	options   Options
+
+	state *namespaced.State
+
+	mu sync.Mutex
+
+	role     role
+	term     uint64
+	votedFor NodeID
+	leader   NodeID
+
+	// log[0] is a sentinel, so that log[i].Index == i
+	log         []Entry
+	commitIndex uint64
+	lastApplied uint64
+
+	// index of the first entry appended in the leader's term
+	termStartIndex uint64
+
+	electionDeadline time.Time
+
+	nextIndex   map[NodeID]uint64
+	matchIndex  map[NodeID]uint64
+	replicating map[NodeID]bool
+
+	proposals map[uint64]proposal
+
+	commitCh    chan struct{}
+	replicateCh chan struct{}
+	appliedCh   chan struct{}
+}
+
+// NewNode creates new Node.
+//
+// Peers are the other members of the cluster.
+func NewNode(id NodeID, peers []NodeID, transport Transport, opts ...Option) *Node {
+	options := DefaultOptions()
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	node := &Node{
+		id:          id,
+		peers:       append([]NodeID(nil), peers...),
+		transport:   transport,
+		options:     options,
+		state:       namespaced.NewState(inmem.Build),
+		log:         []Entry{{}},
+		nextIndex:   map[NodeID]uint64{},
+		matchIndex:  map[NodeID]uint64{},
+		replicating: map[NodeID]bool{},
+		proposals:   map[uint64]proposal{},
+		commitCh:    make(chan struct{}, 1),
+		replicateCh: make(chan struct{}, 1),
+		appliedCh:   make(chan struct{}),
+	}
+
+	node.resetElectionDeadline()
+
+	return node
+}
+
+// ID returns the node ID.
+func (node *Node) ID() NodeID {
+	return node.id
+}
+
+// Leader returns the ID of the leader as known to the node.
+func (node *Node) Leader() NodeID {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	return node.leader
+}
+
+// Run the node until the context is canceled.
+func (node *Node) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	defer wg.Wait()
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		node.runApplier(ctx)
+	}()
+
+	ticker := time.NewTicker(node.options.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-node.replicateCh:
+		}
+
+		node.mu.Lock()
+
+		switch {
+		case node.role == leader:
+			node.replicateAll(ctx)
+		case time.Now().After(node.electionDeadline):
+			node.startElection(ctx)
+		}
+
+		node.mu.Unlock()
+	}
+}
+
+func (node *Node) quorum() int {
+	return (len(node.peers)+1)/2 + 1
+}
+
+func (node *Node) lastIndex() uint64 {
+	return uint64(len(node.log) - 1)
+}
+
+func (node *Node) resetElectionDeadline() {
+	node.electionDeadline = time.Now().Add(node.options.ElectionTimeout + time.Duration(rand.Int63n(int64(node.options.ElectionTimeout))))
+}
+
+func (node *Node) stepDown(term uint64) {
+	if term > node.term {
+		node.term = term
+		node.votedFor = ""
+		node.leader = ""
+	}
+
+	node.role = follower
+	node.resetElectionDeadline()
+}
+
+func (node *Node) startElection(ctx context.Context) {
+	node.term++
+	node.role = candidate
+	node.votedFor = node.id
+	node.leader = ""
+	node.resetElectionDeadline()
+
+	votes := 1
+
+	if votes >= node.quorum() {
+		node.becomeLeader()
+
+		return
+	}
+
+	req := &RequestVoteRequest{
+		Term:         node.term,
+		Candidate:    node.id,
+		LastLogIndex: node.lastIndex(),
+		LastLogTerm:  node.log[node.lastIndex()].Term,
+	}
+
+	for _, peer := range node.peers {
+		peer := peer
+
+		go func() {
+			ctx, cancel := context.WithTimeout(ctx, node.options.ElectionTimeout)
+			defer cancel()
+
+			resp, err := node.transport.RequestVote(ctx, peer, req)
+			if err != nil {
+				return
+			}
+
+			node.mu.Lock()
+			defer node.mu.Unlock()
+
+			if resp.Term > node.term {
+				node.stepDown(resp.Term)
+
+				return
+			}
+
+			if node.role != candidate || node.term != req.Term || !resp.VoteGranted {
+				return
+			}
+
+			votes++
+
+			if votes >= node.quorum() {
+				node.becomeLeader()
+			}
+		}()
+	}
+}
+
+func (node *Node) becomeLeader() {
+	node.role = leader
+	node.leader = node.id
+
+	for _, peer := range node.peers {
+		node.nextIndex[peer] = node.lastIndex() + 1
+		node.matchIndex[peer] = 0
+	}
+
+	// no-op entry commits entries from the previous terms
+	node.termStartIndex = node.appendEntry(nil)
+
+	node.advanceCommitIndex()
+	node.notifyReplicate()
+}
+
+func (node *Node) appendEntry(cmd *Command) uint64 {
+	index := node.lastIndex() + 1
+
+	node.log = append(node.log, Entry{
+		Term:    node.term,
+		Index:   index,
+		Command: cmd,
+	})
+
+	return index
+}
+
+func (node *Node) advanceCommitIndex() {
+	for index := node.lastIndex(); index > node.commitIndex && node.log[index].Term == node.term; index-- {
+		replicas := 1
+
+		for _, peer := range node.peers {
+			if node.matchIndex[peer] >= index {
+				replicas++
+			}
+		}
+
+		if replicas >= node.quorum() {
+			node.commitIndex = index
+
+			node.notifyCommit()
+
+			return
+		}
+	}
+}
+
+func (node *Node) notifyCommit() {
+	select {
+	case node.commitCh <- struct{}{}:
+	default:
+	}
+}
+
+func (node *Node) notifyReplicate() {
+	select {
+	case node.replicateCh <- struct{}{}:
+	default:
+	}
+}
+
+func (node *Node) replicateAll(ctx context.Context) {
+	for _, peer := range node.peers {
+		if node.replicating[peer] {
+			continue
+		}
+
+		node.replicating[peer] = true
+
+		go node.replicate(ctx, peer)
+	}
+}
+
+func (node *Node) appendEntriesRequest(peer NodeID) *AppendEntriesRequest {
+	const maxEntries = 256
+
+	prevIndex := node.nextIndex[peer] - 1
+
+	entries := node.log[prevIndex+1:]
+	if len(entries) > maxEntries {
+		entries = entries[:maxEntries]
+	}
+
+	return &AppendEntriesRequest{
+		Term:         node.term,
+		Leader:       node.id,
+		PrevLogIndex: prevIndex,
+		PrevLogTerm:  node.log[prevIndex].Term,
+		Entries:      append([]Entry(nil), entries...),
+		LeaderCommit: node.commitIndex,
+	}
+}
+
+func (node *Node) replicate(ctx context.Context, peer NodeID) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	defer func() {
+		node.replicating[peer] = false
+	}()
+
+	for node.role == leader {
+		req := node.appendEntriesRequest(peer)
+
+		node.mu.Unlock()
+
+		resp, err := node.sendAppendEntries(ctx, peer, req)
+
+		node.mu.Lock()
+
+		if err != nil || node.role != leader || node.term != req.Term {
+			return
+		}
+
+		if resp.Term > node.term {
+			node.stepDown(resp.Term)
+
+			return
+		}
+
+		if !resp.Success {
+			next := resp.ConflictIndex
+
+			if next >= node.nextIndex[peer] {
+				next = node.nextIndex[peer] - 1
+			}
+
+			if next < 1 {
+				next = 1
+			}
+
+			node.nextIndex[peer] = next
+
+			continue
+		}
+
+		matchIndex := req.PrevLogIndex + uint64(len(req.Entries))
+
+		if matchIndex > node.matchIndex[peer] {
+			node.matchIndex[peer] = matchIndex
+		}
+
+		node.nextIndex[peer] = matchIndex + 1
+
+		node.advanceCommitIndex()
+
+		if node.nextIndex[peer] > node.lastIndex() {
+			return
+		}
+	}
+}
+
+func (node *Node) sendAppendEntries(ctx context.Context, peer NodeID, req *AppendEntriesRequest) (*AppendEntriesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, node.options.ElectionTimeout)
+	defer cancel()
+
+	return node.transport.AppendEntries(ctx, peer, req)
+}
+
+// confirmLeadership checks that the node is still the leader by reaching the quorum of nodes.
+func (node *Node) confirmLeadership(ctx context.Context) error {
+	node.mu.Lock()
+
+	if node.role != leader {
+		node.mu.Unlock()
+
+		return ErrNotLeader
+	}
+
+	term := node.term
+
+	requests := make(map[NodeID]*AppendEntriesRequest, len(node.peers))
+
+	for _, peer := range node.peers {
+		req := node.appendEntriesRequest(peer)
+		req.Entries = nil
+
+		requests[peer] = req
+	}
+
+	node.mu.Unlock()
+
+	acks := make(chan bool, len(requests))
+
+	for peer, req := range requests {
+		peer, req := peer, req
+
+		go func() {
+			resp, err := node.sendAppendEntries(ctx, peer, req)
+			if err != nil {
+				acks <- false
+
+				return
+			}
+
+			if resp.Term > term {
+				node.mu.Lock()
+
+				if resp.Term > node.term {
+					node.stepDown(resp.Term)
+				}
+
+				node.mu.Unlock()
+			}
+
+			acks <- resp.Term == term
+		}()
+	}
+
+	confirmed, failed := 1, 0
+
+	for confirmed < node.quorum() {
+		if failed > len(requests)-(node.quorum()-confirmed) {
+			return ErrNotLeader
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ack := <-acks:
+			if ack {
+				confirmed++
+			} else {
+				failed++
+			}
+		}
+	}
+
+	return nil
+}
+
+// HandleRequestVote implements Handler.
+func (node *Node) HandleRequestVote(req *RequestVoteRequest) *RequestVoteResponse {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	if req.Term > node.term {
+		node.stepDown(req.Term)
+	}
+
+	lastTerm := node.log[node.lastIndex()].Term
+	upToDate := req.LastLogTerm > lastTerm || (req.LastLogTerm == lastTerm && req.LastLogIndex >= node.lastIndex())
+
+	granted := req.Term == node.term && (node.votedFor == "" || node.votedFor == req.Candidate) && upToDate
+
+	if granted {
+		node.votedFor = req.Candidate
+		node.resetElectionDeadline()
+	}
+
+	return &RequestVoteResponse{
+		Term:        node.term,
+		VoteGranted: granted,
+	}
+}
+
+// HandleAppendEntries implements Handler.
+func (node *Node) HandleAppendEntries(req *AppendEntriesRequest) *AppendEntriesResponse {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	if req.Term < node.term {
+		return &AppendEntriesResponse{
+			Term: node.term,
+		}
+	}
+
+	if req.Term > node.term || node.role != follower {
+		node.stepDown(req.Term)
+	}
+
+	node.leader = req.Leader
+	node.resetElectionDeadline()
+
+	if req.PrevLogIndex > node.lastIndex() {
+		return &AppendEntriesResponse{
+			Term:          node.term,
+			ConflictIndex: node.lastIndex() + 1,
+		}
+	}
+
+	if prevTerm := node.log[req.PrevLogIndex].Term; prevTerm != req.PrevLogTerm {
+		// skip the whole conflicting term
+		index := req.PrevLogIndex
+
+		for index > 1 && node.log[index-1].Term == prevTerm {
+			index--
+		}
+
+		return &AppendEntriesResponse{
+			Term:          node.term,
+			ConflictIndex: index,
+		}
+	}
+
+	for _, entry := range req.Entries {
+		if entry.Index <= node.lastIndex() {
+			if node.log[entry.Index].Term == entry.Term {
+				continue
+			}
+
+			node.truncateLog(entry.Index)
+		}
+
+		node.log = append(node.log, entry)
+	}
+
+	if lastNew := req.PrevLogIndex + uint64(len(req.Entries)); req.LeaderCommit > node.commitIndex && lastNew > node.commitIndex {
+		node.commitIndex = req.LeaderCommit
+
+		if lastNew < node.commitIndex {
+			node.commitIndex = lastNew
+		}
+
+		node.notifyCommit()
+	}
+
+	return &AppendEntriesResponse{
+		Term:    node.term,
+		Success: true,
+	}
+}
+
+func (node *Node) truncateLog(index uint64) {
+	node.log = node.log[:index]
+
+	for i, p := range node.proposals {
+		if i >= index {
+			p.ch <- ErrNotLeader
+
+			delete(node.proposals, i)
+		}
+	}
+}
+
+// HandlePropose implements Handler.
+func (node *Node) HandlePropose(ctx context.Context, cmd *Command) (*ProposeResponse, error) {
+	node.mu.Lock()
+
+	if node.role != leader {
+		node.mu.Unlock()
+
+		return nil, ErrNotLeader
+	}
+
+	index := node.appendEntry(cmd)
+
+	p := proposal{
+		term: node.term,
+		ch:   make(chan error, 1),
+	}
+
+	node.proposals[index] = p
+
+	node.advanceCommitIndex()
+	node.notifyReplicate()
+
+	node.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case err := <-p.ch:
+		if errors.Is(err, ErrNotLeader) {
+			return nil, err
+		}
+
+		return &ProposeResponse{
+			Index: index,
+			Error: err,
+		}, nil
+	}
+}
+
+// HandleReadIndex implements Handler.
+func (node *Node) HandleReadIndex(ctx context.Context) (uint64, error) {
+	node.mu.Lock()
+
+	if node.role != leader {
+		node.mu.Unlock()
+
+		return 0, ErrNotLeader
+	}
+
+	termStartIndex := node.termStartIndex
+
+	node.mu.Unlock()
+
+	// commit index is up to date only once an entry from the current term is committed
+	if err := node.waitApplied(ctx, termStartIndex); err != nil {
+		return 0, err
+	}
+
+	node.mu.Lock()
+	index := node.commitIndex
+	node.mu.Unlock()
+
+	if err := node.confirmLeadership(ctx); err != nil {
+		return 0, err
+	}
+
+	return index, nil
+}
+
+func (node *Node) runApplier(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-node.commitCh:
+		}
+
+		node.mu.Lock()
+		entries := append([]Entry(nil), node.log[node.lastApplied+1:node.commitIndex+1]...)
+		node.mu.Unlock()
+
+		for _, entry := range entries {
+			var err error
+
+			if entry.Command != nil {
+				err = entry.Command.apply(node.state)
+			}
+
+			node.mu.Lock()
+
+			node.lastApplied = entry.Index
+
+			if p, ok := node.proposals[entry.Index]; ok {
+				if p.term != entry.Term {
+					err = ErrNotLeader
+				}
+
+				p.ch <- err
+
+				delete(node.proposals, entry.Index)
+			}
+
+			close(node.appliedCh)
+			node.appliedCh = make(chan struct{})
+
+			node.mu.Unlock()
+		}
+	}
+}
+
+func (node *Node) waitApplied(ctx context.Context, index uint64) error {
+	for {
+		node.mu.Lock()
+		lastApplied, ch := node.lastApplied, node.appliedCh
+		node.mu.Unlock()
+
+		if lastApplied >= index {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ch:
+		}
+	}
+}
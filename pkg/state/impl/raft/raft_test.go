@@ -0,0 +1,233 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package raft_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/conformance"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/raft"
+)
+
+type cluster struct {
+	network *raft.InmemNetwork
+	nodes   []*raft.Node
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newCluster(t *testing.T, size int) *cluster {
+	c := &cluster{
+		network: raft.NewInmemNetwork(),
+	}
+
+	ids := make([]raft.NodeID, size)
+
+	for i := range ids {
+		ids[i] = raft.NodeID(fmt.Sprintf("node%d", i))
+	}
+
+	for i, id := range ids {
+		peers := append(append([]raft.NodeID(nil), ids[:i]...), ids[i+1:]...)
+
+		node := raft.NewNode(id, peers, c.network.Transport(id),
+			raft.WithHeartbeatInterval(10*time.Millisecond),
+			raft.WithElectionTimeout(100*time.Millisecond),
+		)
+
+		c.network.Register(id, node)
+		c.nodes = append(c.nodes, node)
+	}
+
+	var ctx context.Context
+
+	ctx, c.cancel = context.WithCancel(context.Background())
+
+	for _, node := range c.nodes {
+		node := node
+
+		c.wg.Add(1)
+
+		go func() {
+			defer c.wg.Done()
+
+			assert.NoError(t, node.Run(ctx))
+		}()
+	}
+
+	t.Cleanup(func() {
+		c.cancel()
+		c.wg.Wait()
+	})
+
+	return c
+}
+
+func (c *cluster) leader(t *testing.T, except ...raft.NodeID) *raft.Node {
+	var result *raft.Node
+
+	require.Eventually(t, func() bool {
+		for _, node := range c.nodes {
+			if node.Leader() != node.ID() {
+				continue
+			}
+
+			excluded := false
+
+			for _, id := range except {
+				excluded = excluded || id == node.ID()
+			}
+
+			if !excluded {
+				result = node
+
+				return true
+			}
+		}
+
+		return false
+	}, 10*time.Second, 10*time.Millisecond)
+
+	return result
+}
+
+func (c *cluster) follower(t *testing.T) *raft.Node {
+	leader := c.leader(t)
+
+	for _, node := range c.nodes {
+		if node != leader {
+			return node
+		}
+	}
+
+	return nil
+}
+
+func TestInterfaces(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*state.CoreState)(nil), new(raft.Node))
+	assert.Implements(t, (*state.Discovery)(nil), new(raft.Node))
+	assert.Implements(t, (*raft.Handler)(nil), new(raft.Node))
+}
+
+func TestSingleNodeConformance(t *testing.T) {
+	t.Parallel()
+
+	c := newCluster(t, 1)
+
+	suite.Run(t, &conformance.StateSuite{
+		State:      state.WrapCore(c.leader(t)),
+		Namespaces: []resource.Namespace{"default", "controller", "system", "runtime"},
+	})
+}
+
+func TestFollowerConformance(t *testing.T) {
+	t.Parallel()
+
+	c := newCluster(t, 3)
+
+	suite.Run(t, &conformance.StateSuite{
+		State:      state.WrapCore(c.follower(t)),
+		Namespaces: []resource.Namespace{"default", "controller", "system", "runtime"},
+	})
+}
+
+func TestReplication(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c := newCluster(t, 3)
+
+	ch := make(chan state.Event)
+
+	path := conformance.NewPathResource("default", "/var")
+
+	for _, node := range c.nodes {
+		require.NoError(t, node.Watch(ctx, path.Metadata(), ch))
+
+		event := <-ch
+		assert.Equal(t, state.Destroyed, event.Type)
+	}
+
+	require.NoError(t, c.follower(t).Create(ctx, path))
+
+	err := c.leader(t).Create(ctx, path)
+	assert.True(t, state.IsConflictError(err))
+
+	for range c.nodes {
+		event := <-ch
+		assert.Equal(t, state.Created, event.Type)
+		assert.Equal(t, path.Metadata().ID(), event.Resource.Metadata().ID())
+	}
+
+	for _, node := range c.nodes {
+		_, err = node.Get(ctx, path.Metadata())
+		assert.NoError(t, err)
+
+		list, err := node.List(ctx, path.Metadata(), state.WithListAllowStale())
+		require.NoError(t, err)
+		assert.Len(t, list.Items, 1)
+	}
+}
+
+func TestLeaderFailover(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c := newCluster(t, 3)
+
+	oldLeader := c.leader(t)
+
+	require.NoError(t, oldLeader.Create(ctx, conformance.NewPathResource("default", "/var")))
+
+	c.network.Isolate(oldLeader.ID())
+
+	// stale reads are served by the isolated node
+	_, err := oldLeader.Get(ctx, conformance.NewPathResource("default", "/var").Metadata(), state.WithGetAllowStale())
+	require.NoError(t, err)
+
+	// linearizable reads can't be served without the quorum
+	shortCtx, shortCancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer shortCancel()
+
+	_, err = oldLeader.Get(shortCtx, conformance.NewPathResource("default", "/var").Metadata())
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	newLeader := c.leader(t, oldLeader.ID())
+
+	require.NoError(t, newLeader.Create(ctx, conformance.NewPathResource("default", "/etc")))
+
+	c.network.Heal(oldLeader.ID())
+
+	// the old leader catches up with the new leader
+	_, err = oldLeader.Get(ctx, conformance.NewPathResource("default", "/etc").Metadata())
+	require.NoError(t, err)
+
+	require.NoError(t, oldLeader.Destroy(ctx, conformance.NewPathResource("default", "/var").Metadata()))
+
+	for _, node := range c.nodes {
+		list, err := node.List(ctx, conformance.NewPathResource("default", "").Metadata())
+		require.NoError(t, err)
+		require.Len(t, list.Items, 1)
+		assert.Equal(t, "/etc", list.Items[0].Metadata().ID())
+	}
+}
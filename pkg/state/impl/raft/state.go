@@ -0,0 +1,178 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package raft
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/state"
+)
+
+// retry calls f on the leader until the leader is found.
+func (node *Node) retry(ctx context.Context, f func(NodeID) error) error {
+	for {
+		node.mu.Lock()
+		leaderID := node.leader
+		node.mu.Unlock()
+
+		err := ErrNotLeader
+
+		if leaderID != "" {
+			err = f(leaderID)
+		}
+
+		if !errors.Is(err, ErrNotLeader) && !errors.Is(err, ErrUnreachable) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(node.options.HeartbeatInterval):
+		}
+	}
+}
+
+// propose the command to the leader and wait for it to be applied locally.
+func (node *Node) propose(ctx context.Context, cmd *Command) error {
+	cmd.Owner = state.OwnerFromContext(ctx)
+
+	var resp *ProposeResponse
+
+	if err := node.retry(ctx, func(leaderID NodeID) error {
+		var err error
+
+		if leaderID == node.id {
+			resp, err = node.HandlePropose(ctx, cmd)
+		} else {
+			resp, err = node.transport.Propose(ctx, leaderID, cmd)
+		}
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if err := node.waitApplied(ctx, resp.Index); err != nil {
+		return err
+	}
+
+	return resp.Error
+}
+
+// barrier waits for the local state to catch up with the writes committed before the call.
+func (node *Node) barrier(ctx context.Context) error {
+	var index uint64
+
+	if err := node.retry(ctx, func(leaderID NodeID) error {
+		var err error
+
+		if leaderID == node.id {
+			index, err = node.HandleReadIndex(ctx)
+		} else {
+			index, err = node.transport.ReadIndex(ctx, leaderID)
+		}
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return node.waitApplied(ctx, index)
+}
+
+// Get a resource.
+//
+// Get is linearizable unless state.WithGetAllowStale is used.
+func (node *Node) Get(ctx context.Context, resourcePointer resource.Pointer, opts ...state.GetOption) (resource.Resource, error) {
+	var options state.GetOptions
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if !options.AllowStale {
+		if err := node.barrier(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return node.state.Get(ctx, resourcePointer)
+}
+
+// List resources.
+//
+// List is linearizable unless state.WithListAllowStale is used.
+func (node *Node) List(ctx context.Context, resourceKind resource.Kind, opts ...state.ListOption) (resource.List, error) {
+	var options state.ListOptions
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if !options.AllowStale {
+		if err := node.barrier(ctx); err != nil {
+			return resource.List{}, err
+		}
+	}
+
+	return node.state.List(ctx, resourceKind)
+}
+
+// Create a resource.
+func (node *Node) Create(ctx context.Context, res resource.Resource, opts ...state.CreateOption) error {
+	return node.propose(ctx, &Command{
+		Op:       OpCreate,
+		Resource: res.DeepCopy(),
+	})
+}
+
+// Update a resource.
+func (node *Node) Update(ctx context.Context, curVersion resource.Version, newResource resource.Resource, opts ...state.UpdateOption) error {
+	var options state.UpdateOptions
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return node.propose(ctx, &Command{
+		Op:            OpUpdate,
+		Resource:      newResource.DeepCopy(),
+		CurVersion:    curVersion,
+		UpdateOptions: options,
+	})
+}
+
+// Destroy a resource.
+func (node *Node) Destroy(ctx context.Context, resourcePointer resource.Pointer, opts ...state.DestroyOption) error {
+	return node.propose(ctx, &Command{
+		Op:      OpDestroy,
+		Pointer: resource.NewMetadata(resourcePointer.Namespace(), resourcePointer.Type(), resourcePointer.ID(), resource.VersionUndefined),
+	})
+}
+
+// Watch a resource.
+//
+// Watch is served by the local state.
+func (node *Node) Watch(ctx context.Context, resourcePointer resource.Pointer, ch chan<- state.Event, opts ...state.WatchOption) error {
+	return node.state.Watch(ctx, resourcePointer, ch, opts...)
+}
+
+// WatchKind all resources by type.
+//
+// WatchKind is served by the local state.
+func (node *Node) WatchKind(ctx context.Context, resourceKind resource.Kind, ch chan<- state.Event, opts ...state.WatchKindOption) error {
+	return node.state.WatchKind(ctx, resourceKind, ch, opts...)
+}
+
+// Discover implements state.Discovery.
+//
+// Discover is served by the local state.
+func (node *Node) Discover(ctx context.Context) ([]state.NamespaceSummary, error) {
+	return node.state.Discover(ctx)
+}
@@ -0,0 +1,186 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package raft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrNotLeader is returned when a request which should be processed by the leader hits another node.
+	ErrNotLeader = errors.New("node is not the leader")
+
+	// ErrUnreachable is returned by the Transport when the target node can't be reached.
+	ErrUnreachable = errors.New("node is unreachable")
+)
+
+// RequestVoteRequest is sent by candidates to gather votes.
+type RequestVoteRequest struct {
+	Term         uint64
+	Candidate    NodeID
+	LastLogIndex uint64
+	LastLogTerm  uint64
+}
+
+// RequestVoteResponse is the reply to RequestVoteRequest.
+type RequestVoteResponse struct {
+	Term        uint64
+	VoteGranted bool
+}
+
+// AppendEntriesRequest is sent by the leader to replicate log entries and as a heartbeat.
+type AppendEntriesRequest struct {
+	Term         uint64
+	Leader       NodeID
+	PrevLogIndex uint64
+	PrevLogTerm  uint64
+	Entries      []Entry
+	LeaderCommit uint64
+}
+
+// AppendEntriesResponse is the reply to AppendEntriesRequest.
+type AppendEntriesResponse struct {
+	Term    uint64
+	Success bool
+
+	// ConflictIndex is the index the leader should retry from if Success is false.
+	ConflictIndex uint64
+}
+
+// ProposeResponse is the reply to the command proposed to the leader.
+type ProposeResponse struct {
+	// Index of the log entry holding the command.
+	Index uint64
+
+	// Error returned by the state when the command was applied.
+	Error error
+}
+
+// Handler processes requests delivered by the Transport.
+type Handler interface {
+	HandleRequestVote(*RequestVoteRequest) *RequestVoteResponse
+	HandleAppendEntries(*AppendEntriesRequest) *AppendEntriesResponse
+	HandlePropose(context.Context, *Command) (*ProposeResponse, error)
+	HandleReadIndex(context.Context) (uint64, error)
+}
+
+// Transport delivers requests from the node to the other nodes of the cluster.
+//
+// Transport should return ErrUnreachable if the request wasn't delivered.
+type Transport interface {
+	RequestVote(context.Context, NodeID, *RequestVoteRequest) (*RequestVoteResponse, error)
+	AppendEntries(context.Context, NodeID, *AppendEntriesRequest) (*AppendEntriesResponse, error)
+	Propose(context.Context, NodeID, *Command) (*ProposeResponse, error)
+	ReadIndex(context.Context, NodeID) (uint64, error)
+}
+
+// InmemNetwork connects nodes running in the same process.
+//
+// InmemNetwork supports isolating nodes to simulate network partitions.
+type InmemNetwork struct {
+	mu       sync.Mutex
+	handlers map[NodeID]Handler
+	isolated map[NodeID]bool
+}
+
+// NewInmemNetwork creates new InmemNetwork.
+func NewInmemNetwork() *InmemNetwork {
+	return &InmemNetwork{
+		handlers: map[NodeID]Handler{},
+		isolated: map[NodeID]bool{},
+	}
+}
+
+// Register the node in the network.
+func (network *InmemNetwork) Register(id NodeID, handler Handler) {
+	network.mu.Lock()
+	defer network.mu.Unlock()
+
+	network.handlers[id] = handler
+}
+
+// Isolate the node: all requests from and to the node fail.
+func (network *InmemNetwork) Isolate(id NodeID) {
+	network.mu.Lock()
+	defer network.mu.Unlock()
+
+	network.isolated[id] = true
+}
+
+// Heal reconnects the isolated node.
+func (network *InmemNetwork) Heal(id NodeID) {
+	network.mu.Lock()
+	defer network.mu.Unlock()
+
+	delete(network.isolated, id)
+}
+
+// Transport returns the Transport for the node.
+func (network *InmemNetwork) Transport(id NodeID) Transport {
+	return &inmemTransport{
+		network: network,
+		source:  id,
+	}
+}
+
+type inmemTransport struct {
+	network *InmemNetwork
+	source  NodeID
+}
+
+func (transport *inmemTransport) target(id NodeID) (Handler, error) {
+	transport.network.mu.Lock()
+	defer transport.network.mu.Unlock()
+
+	if transport.network.isolated[transport.source] || transport.network.isolated[id] {
+		return nil, fmt.Errorf("error sending request from %q to %q: %w", transport.source, id, ErrUnreachable)
+	}
+
+	handler, ok := transport.network.handlers[id]
+	if !ok {
+		return nil, fmt.Errorf("node %q is not registered: %w", id, ErrUnreachable)
+	}
+
+	return handler, nil
+}
+
+func (transport *inmemTransport) RequestVote(ctx context.Context, id NodeID, req *RequestVoteRequest) (*RequestVoteResponse, error) {
+	handler, err := transport.target(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler.HandleRequestVote(req), nil
+}
+
+func (transport *inmemTransport) AppendEntries(ctx context.Context, id NodeID, req *AppendEntriesRequest) (*AppendEntriesResponse, error) {
+	handler, err := transport.target(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler.HandleAppendEntries(req), nil
+}
+
+func (transport *inmemTransport) Propose(ctx context.Context, id NodeID, cmd *Command) (*ProposeResponse, error) {
+	handler, err := transport.target(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler.HandlePropose(ctx, cmd)
+}
+
+func (transport *inmemTransport) ReadIndex(ctx context.Context, id NodeID) (uint64, error) {
+	handler, err := transport.target(id)
+	if err != nil {
+		return 0, err
+	}
+
+	return handler.HandleReadIndex(ctx)
+}
@@ -5,17 +5,35 @@
 package state
 
 // GetOptions for the CoreState.Get function.
-type GetOptions struct{}
+type GetOptions struct {
+	AllowStale bool
+}
 
 // GetOption builds GetOptions.
 type GetOption func(*GetOptions)
 
+// WithGetAllowStale allows replicated states to serve Get from the local replica which might be lagging behind.
+func WithGetAllowStale() GetOption {
+	return func(opts *GetOptions) {
+		opts.AllowStale = true
+	}
+}
+
 // ListOptions for the CoreState.List function.
-type ListOptions struct{}
+type ListOptions struct {
+	AllowStale bool
+}
 
 // ListOption builds ListOptions.
 type ListOption func(*ListOptions)
 
+// WithListAllowStale allows replicated states to serve List from the local replica which might be lagging behind.
+func WithListAllowStale() ListOption {
+	return func(opts *ListOptions) {
+		opts.AllowStale = true
+	}
+}
+
 // CreateOptions for the CoreState.Create function.
 type CreateOptions struct{}
 
@@ -23,11 +41,32 @@ type CreateOptions struct{}
 type CreateOption func(*CreateOptions)
 
 // UpdateOptions for the CoreState.Update function.
-type UpdateOptions struct{}
+type UpdateOptions struct {
+	ForcePhaseTransition  bool
+	ForceFinalizerRemoval bool
+}
 
 // UpdateOption builds UpdateOptions.
 type UpdateOption func(*UpdateOptions)
 
+// WithForcePhaseTransition skips validation of the resource phase transition on update.
+//
+// This allows to bring a resource which is being torn down back to running.
+func WithForcePhaseTransition() UpdateOption {
+	return func(opts *UpdateOptions) {
+		opts.ForcePhaseTransition = true
+	}
+}
+
+// WithForceFinalizerRemoval skips the ownership check for the finalizers on update.
+//
+// By default owned finalizers can be only added or removed by their owner (see WithOwner).
+func WithForceFinalizerRemoval() UpdateOption {
+	return func(opts *UpdateOptions) {
+		opts.ForceFinalizerRemoval = true
+	}
+}
+
 // TeardownOptions for the CoreState.Teardown function.
 type TeardownOptions struct{}
 
@@ -60,3 +99,43 @@ func WithBootstrapContents(enable bool) WatchKindOption {
 		opts.BootstrapContents = enable
 	}
 }
+
+// ApplyOptions for the State.Apply function.
+type ApplyOptions struct {
+	Updater         UpdaterFunc
+	Force           bool
+	OnlyIfUnchanged bool
+}
+
+// ApplyOption builds ApplyOptions.
+type ApplyOption func(*ApplyOptions)
+
+// WithApplyUpdater sets the function which builds the desired resource state.
+//
+// Updater is called on the copy of the stored resource (or on the copy of the
+// resource passed to Apply if the resource doesn't exist yet).
+// Without the updater, spec of the resource passed to Apply replaces the stored one.
+func WithApplyUpdater(f UpdaterFunc) ApplyOption {
+	return func(opts *ApplyOptions) {
+		opts.Updater = f
+	}
+}
+
+// WithApplyForce overwrites phase and finalizers of the stored resource with the values from the resource passed to Apply.
+//
+// By default Apply preserves phase and finalizers of the stored resource.
+// Phase transition and finalizer ownership are not validated with this option.
+func WithApplyForce() ApplyOption {
+	return func(opts *ApplyOptions) {
+		opts.Force = true
+	}
+}
+
+// WithApplyOnlyIfUnchanged updates the resource only if the stored version matches the version of the resource passed to Apply.
+//
+// Conflicts are returned to the caller instead of being retried.
+func WithApplyOnlyIfUnchanged() ApplyOption {
+	return func(opts *ApplyOptions) {
+		opts.OnlyIfUnchanged = true
+	}
+}
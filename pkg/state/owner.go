@@ -0,0 +1,68 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+)
+
+type ownerKey struct{}
+
+// WithOwner attaches the identity of the controller or client changing the state to the context.
+func WithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+// OwnerFromContext returns the identity attached to the context with WithOwner.
+func OwnerFromContext(ctx context.Context) string {
+	owner, _ := ctx.Value(ownerKey{}).(string) //nolint: errcheck
+
+	return owner
+}
+
+// CheckFinalizerOwnership verifies that owned finalizers are added or removed only by their owner.
+//
+// CheckFinalizerOwnership should be used by the CoreState implementations on update.
+// Finalizers can't be changed if the owner is not valid (see resource.ValidateOwner).
+func CheckFinalizerOwnership(owner string, current, updated *resource.Metadata) error {
+	check := func(from, to resource.Finalizers) error {
+		for _, fin := range from {
+			if containsFinalizer(to, fin) {
+				continue
+			}
+
+			if owner != "" {
+				if err := resource.ValidateOwner(owner); err != nil {
+					return err
+				}
+			}
+
+			if finOwner, _ := resource.FinalizerOwner(fin); finOwner != "" && finOwner != owner {
+				return fmt.Errorf("finalizer %q is owned by %q, change attempted by %q", fin, finOwner, owner)
+			}
+		}
+
+		return nil
+	}
+
+	if err := check(*current.Finalizers(), *updated.Finalizers()); err != nil {
+		return err
+	}
+
+	return check(*updated.Finalizers(), *current.Finalizers())
+}
+
+func containsFinalizer(fins resource.Finalizers, fin resource.Finalizer) bool {
+	for _, f := range fins {
+		if f == fin {
+			return true
+		}
+	}
+
+	return false
+}
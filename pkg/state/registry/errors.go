@@ -0,0 +1,61 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package registry
+
+import "fmt"
+
+type eNotFound struct {
+	error
+}
+
+func (eNotFound) NotFoundError() {}
+
+// ErrNamespaceNotFound generates error compatible with state.ErrNotFound.
+func ErrNamespaceNotFound(ns string) error {
+	return eNotFound{
+		fmt.Errorf("namespace %q is not registered", ns),
+	}
+}
+
+// ErrTypeNotFound generates error compatible with state.ErrNotFound.
+func ErrTypeNotFound(name string) error {
+	return eNotFound{
+		fmt.Errorf("resource type %q is not registered", name),
+	}
+}
+
+type eConflict struct {
+	error
+}
+
+func (eConflict) ConflictError() {}
+
+// ErrAliasConflict generates error compatible with state.ErrConflict.
+func ErrAliasConflict(alias, typ, existingType string) error {
+	return eConflict{
+		fmt.Errorf("alias %q of resource type %q conflicts with resource type %q", alias, typ, existingType),
+	}
+}
+
+// ErrNamespaceNotEmpty generates error compatible with state.ErrConflict.
+func ErrNamespaceNotEmpty(ns string, typ string) error {
+	return eConflict{
+		fmt.Errorf("namespace %q is not empty: it contains resources of type %q", ns, typ),
+	}
+}
+
+// ErrTypeInUse generates error compatible with state.ErrConflict.
+func ErrTypeInUse(typ, ns string) error {
+	return eConflict{
+		fmt.Errorf("resource type %q is in use: namespace %q contains resources of that type", typ, ns),
+	}
+}
+
+// ErrIncompatibleDefinition generates error compatible with state.ErrConflict.
+func ErrIncompatibleDefinition(typ, reason, ns string) error {
+	return eConflict{
+		fmt.Errorf("incompatible update of resource type %q: %s, while namespace %q contains resources of that type", typ, reason, ns),
+	}
+}
@@ -0,0 +1,96 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/resource/schema"
+)
+
+// SchemaFormat is the format of the exported resource definitions.
+type SchemaFormat int
+
+// Schema formats.
+const (
+	// OpenAPI v3.1 document with resource schemas as components.
+	SchemaOpenAPI SchemaFormat = iota
+	// JSON Schema (draft 2020-12) document which matches any registered resource.
+	SchemaJSON
+)
+
+// Export all the registered resource definitions as the schema document.
+//
+// Resource spec is described if the Go type of the resource is registered in the type registry,
+// otherwise spec is described as any value.
+func (registry *ResourceRegistry) Export(ctx context.Context, format SchemaFormat) ([]byte, error) {
+	definitions, err := listDefinitions(ctx, registry.state)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make(map[string]*schema.Schema, len(definitions))
+
+	for i := range definitions {
+		schemas[definitions[i].Type] = registry.resourceSchema(&definitions[i])
+	}
+
+	var doc interface{}
+
+	switch format {
+	case SchemaOpenAPI:
+		doc = map[string]interface{}{
+			"openapi": "3.1.0",
+			"info": map[string]interface{}{
+				"title":   "Resources",
+				"version": "v1",
+			},
+			"paths": map[string]interface{}{},
+			"components": map[string]interface{}{
+				"schemas": schemas,
+			},
+		}
+	case SchemaJSON:
+		refs := make([]*schema.Schema, 0, len(definitions))
+
+		for _, definition := range definitions {
+			refs = append(refs, &schema.Schema{Ref: "#/$defs/" + definition.Type})
+		}
+
+		doc = map[string]interface{}{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$defs":   schemas,
+			"anyOf":   refs,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported schema format %d", format)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func (registry *ResourceRegistry) resourceSchema(definition *meta.ResourceDefinitionSpec) *schema.Schema {
+	var spec *schema.Schema
+
+	if r, ok := registry.types.New(definition.Type); ok {
+		if _, isAny := r.(*resource.Any); !isAny && r.Spec() != nil {
+			spec = schema.ForType(reflect.TypeOf(r.Spec()))
+		}
+	}
+
+	result := schema.Resource(definition.Type, spec)
+	result.Description = fmt.Sprintf("%s resource.", definition.DisplayType)
+
+	if definition.DefaultNamespace != "" {
+		result.Properties["metadata"].Properties["namespace"].Description += fmt.Sprintf(" Default: %q.", definition.DefaultNamespace)
+	}
+
+	return result
+}
@@ -0,0 +1,84 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package registry_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/namespaced"
+	"github.com/talos-systems/os-runtime/pkg/state/registry"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	st := state.WrapCore(namespaced.NewState(inmem.Build))
+
+	r := registry.NewResourceRegistry(st)
+	require.NoError(t, r.RegisterDefault(ctx))
+
+	// definition registered without the Go type
+	rd, err := meta.NewResourceDefinition(meta.ResourceDefinitionSpec{
+		Type: "Widgets.test.cosi.dev",
+	})
+	require.NoError(t, err)
+	require.NoError(t, st.Create(ctx, rd))
+
+	out, err := r.Export(ctx, registry.SchemaOpenAPI)
+	require.NoError(t, err)
+
+	var doc struct {
+		OpenAPI    string `json:"openapi"`
+		Components struct {
+			Schemas map[string]struct {
+				Description string                            `json:"description"`
+				Properties  map[string]map[string]interface{} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+
+	require.NoError(t, json.Unmarshal(out, &doc))
+
+	assert.Equal(t, "3.1.0", doc.OpenAPI)
+	assert.Len(t, doc.Components.Schemas, 3)
+
+	ns := doc.Components.Schemas[meta.NamespaceType]
+	assert.Equal(t, "Namespace resource.", ns.Description)
+	assert.Equal(t, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"description": map[string]interface{}{"type": "string"},
+		},
+	}, ns.Properties["spec"])
+
+	assert.Equal(t, map[string]interface{}{}, doc.Components.Schemas["Widgets.test.cosi.dev"].Properties["spec"])
+
+	out, err = r.Export(ctx, registry.SchemaJSON)
+	require.NoError(t, err)
+
+	var jsonSchema struct {
+		Schema string                 `json:"$schema"`
+		Defs   map[string]interface{} `json:"$defs"`
+		AnyOf  []map[string]string    `json:"anyOf"`
+	}
+
+	require.NoError(t, json.Unmarshal(out, &jsonSchema))
+
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", jsonSchema.Schema)
+	assert.Len(t, jsonSchema.Defs, 3)
+	assert.Contains(t, jsonSchema.AnyOf, map[string]string{"$ref": "#/$defs/Namespaces.meta.cosi.dev"})
+
+	_, err = r.Export(ctx, registry.SchemaFormat(42))
+	assert.EqualError(t, err, "unsupported schema format 42")
+}
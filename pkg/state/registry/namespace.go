@@ -6,22 +6,95 @@ package registry
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
 
 	"github.com/talos-systems/os-runtime/pkg/resource"
 	"github.com/talos-systems/os-runtime/pkg/resource/meta"
 	"github.com/talos-systems/os-runtime/pkg/state"
 )
 
+// NamespaceDropper is implemented by the backends which keep per-namespace state.
+//
+// The namespace is closed while it is torn down, so that resources created concurrently
+// with the teardown are rejected instead of being lost when the namespace is dropped.
+type NamespaceDropper interface {
+	CloseNamespace(resource.Namespace)
+	OpenNamespace(resource.Namespace)
+	DropNamespace(resource.Namespace)
+}
+
+// NamespaceRegistryOptions configure NamespaceRegistry.
+type NamespaceRegistryOptions struct {
+	Dropper        NamespaceDropper
+	Logger         *log.Logger
+	RetryInterval  time.Duration
+	CleanupTimeout time.Duration
+}
+
+// NamespaceRegistryOption builds NamespaceRegistryOptions.
+type NamespaceRegistryOption func(*NamespaceRegistryOptions)
+
+// WithNamespaceDropper sets the backend which drops namespace state once the namespace is destroyed.
+//
+// Creating resources in the namespace is rejected while it is torn down, and namespace state is dropped
+// only if the state discovery (see state.Discovery) confirms that the namespace is empty.
+func WithNamespaceDropper(dropper NamespaceDropper) NamespaceRegistryOption {
+	return func(opts *NamespaceRegistryOptions) {
+		opts.Dropper = dropper
+	}
+}
+
+// WithNamespaceLogger sets the logger for the errors of the namespace cleanup.
+func WithNamespaceLogger(logger *log.Logger) NamespaceRegistryOption {
+	return func(opts *NamespaceRegistryOptions) {
+		opts.Logger = logger
+	}
+}
+
+// WithNamespaceRetryInterval sets the interval between the attempts to clean up the namespace.
+func WithNamespaceRetryInterval(interval time.Duration) NamespaceRegistryOption {
+	return func(opts *NamespaceRegistryOptions) {
+		opts.RetryInterval = interval
+	}
+}
+
+// WithNamespaceCleanupTimeout limits the duration of a single attempt to clean up the namespace.
+//
+// Cleanup attempt which times out (e.g. waiting for the finalizers) is retried.
+func WithNamespaceCleanupTimeout(timeout time.Duration) NamespaceRegistryOption {
+	return func(opts *NamespaceRegistryOptions) {
+		opts.CleanupTimeout = timeout
+	}
+}
+
 // NamespaceRegistry facilitates tracking namespaces.
 type NamespaceRegistry struct {
 	state state.State
+
+	options NamespaceRegistryOptions
 }
 
 // NewNamespaceRegistry creates new NamespaceRegistry.
-func NewNamespaceRegistry(state state.State) *NamespaceRegistry {
-	return &NamespaceRegistry{
+func NewNamespaceRegistry(state state.State, opts ...NamespaceRegistryOption) *NamespaceRegistry {
+	registry := &NamespaceRegistry{
 		state: state,
+		options: NamespaceRegistryOptions{
+			Logger:         log.Default(),
+			RetryInterval:  5 * time.Second,
+			CleanupTimeout: time.Minute,
+		},
 	}
+
+	for _, opt := range opts {
+		opt(&registry.options)
+	}
+
+	return registry
 }
 
 // RegisterDefault registers default namespaces.
@@ -31,7 +104,432 @@ func (registry *NamespaceRegistry) RegisterDefault(ctx context.Context) error {
 
 // Register a namespace.
 func (registry *NamespaceRegistry) Register(ctx context.Context, ns resource.Namespace, description string) error {
-	return registry.state.Create(ctx, meta.NewNamespace(ns, meta.NamespaceSpec{
+	if err := meta.ValidateNamespaceName(ns); err != nil {
+		return fmt.Errorf("error registering namespace: %w", err)
+	}
+
+	if err := registry.state.Create(ctx, meta.NewNamespace(ns, meta.NamespaceSpec{
 		Description: description,
-	}))
+	})); err != nil {
+		return err
+	}
+
+	// namespace might have been left closed by an interrupted teardown
+	registry.openNamespace(ns)
+
+	return nil
+}
+
+// List registered namespaces.
+func (registry *NamespaceRegistry) List(ctx context.Context) ([]*meta.Namespace, error) {
+	list, err := registry.state.List(ctx, resource.NewMetadata(meta.NamespaceName, meta.NamespaceType, "", resource.VersionUndefined))
+	if err != nil {
+		return nil, fmt.Errorf("error listing namespaces: %w", err)
+	}
+
+	result := make([]*meta.Namespace, 0, len(list.Items))
+
+	for _, item := range list.Items {
+		namespace, err := toNamespace(item)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, namespace)
+	}
+
+	return result, nil
+}
+
+// Describe a registered namespace.
+func (registry *NamespaceRegistry) Describe(ctx context.Context, ns resource.Namespace) (*meta.Namespace, error) {
+	r, err := registry.state.Get(ctx, namespacePointer(ns))
+	if err != nil {
+		if state.IsNotFoundError(err) {
+			return nil, ErrNamespaceNotFound(ns)
+		}
+
+		return nil, err
+	}
+
+	return toNamespace(r)
+}
+
+// UpdateDescription of a registered namespace.
+func (registry *NamespaceRegistry) UpdateDescription(ctx context.Context, ns resource.Namespace, description string) error {
+	_, err := registry.state.UpdateWithConflicts(ctx, namespacePointer(ns), func(r resource.Resource) error {
+		namespace, ok := r.(*meta.Namespace)
+		if !ok {
+			return fmt.Errorf("unexpected resource type %T", r)
+		}
+
+		namespace.TypedSpec().Description = description
+
+		return nil
+	})
+	if state.IsNotFoundError(err) {
+		return ErrNamespaceNotFound(ns)
+	}
+
+	return err
+}
+
+// UnregisterOptions configure NamespaceRegistry.Unregister.
+type UnregisterOptions struct {
+	Cascade bool
+}
+
+// UnregisterOption builds UnregisterOptions.
+type UnregisterOption func(*UnregisterOptions)
+
+// WithCascade destroys all the resources in the namespace before unregistering it.
+//
+// Resources are torn down first, and destroyed when their finalizers are removed.
+func WithCascade() UnregisterOption {
+	return func(opts *UnregisterOptions) {
+		opts.Cascade = true
+	}
+}
+
+// Unregister a namespace.
+//
+// Unregister fails if the namespace contains any resources, unless cascading deletion is requested.
+func (registry *NamespaceRegistry) Unregister(ctx context.Context, ns resource.Namespace, opts ...UnregisterOption) error {
+	var options UnregisterOptions
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if ns == meta.NamespaceName {
+		return fmt.Errorf("namespace %q can't be unregistered", ns)
+	}
+
+	if _, err := registry.Describe(ctx, ns); err != nil {
+		return err
+	}
+
+	if options.Cascade {
+		return registry.destroy(ctx, ns)
+	}
+
+	// resources created after the emptiness check would be dropped with the namespace
+	registry.closeNamespace(ns)
+
+	if err := registry.checkNoResources(ctx, ns); err != nil {
+		registry.openNamespace(ns)
+
+		return err
+	}
+
+	return registry.destroy(ctx, ns)
+}
+
+// checkNoResources verifies that the namespace has no resources of any known type.
+func (registry *NamespaceRegistry) checkNoResources(ctx context.Context, ns resource.Namespace) error {
+	types, err := registry.namespaceTypes(ctx, ns)
+	if err != nil {
+		return err
+	}
+
+	for _, typ := range types {
+		list, err := registry.state.List(ctx, resource.NewMetadata(ns, typ, "", resource.VersionUndefined))
+		if err != nil {
+			return fmt.Errorf("error listing resources of type %q: %w", typ, err)
+		}
+
+		if len(list.Items) > 0 {
+			return ErrNamespaceNotEmpty(ns, typ)
+		}
+	}
+
+	return nil
+}
+
+// namespaceTypes returns the resource types which might have resources in the namespace.
+//
+// Types of the registered resource definitions are combined with the types reported by
+// the state discovery (see state.Discovery), so that resources without definitions are not missed.
+func (registry *NamespaceRegistry) namespaceTypes(ctx context.Context, ns resource.Namespace) ([]resource.Type, error) {
+	definitions, err := listDefinitions(ctx, registry.state)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]resource.Type, 0, len(definitions))
+	seen := make(map[resource.Type]struct{}, len(definitions))
+
+	add := func(typ resource.Type) {
+		if _, ok := seen[typ]; !ok {
+			seen[typ] = struct{}{}
+			types = append(types, typ)
+		}
+	}
+
+	for _, definition := range definitions {
+		add(definition.Type)
+	}
+
+	discovery, ok := registry.state.(state.Discovery)
+	if !ok {
+		return types, nil
+	}
+
+	summaries, err := discovery.Discover(ctx)
+	if err != nil {
+		// discovery is optional, so only registered types are checked if the state doesn't support it
+		return types, nil
+	}
+
+	for _, summary := range summaries {
+		if summary.Namespace != ns {
+			continue
+		}
+
+		for _, typeSummary := range summary.Types {
+			add(typeSummary.Type)
+		}
+	}
+
+	return types, nil
+}
+
+// Run cleans up namespaces which are being torn down until the context is canceled.
+//
+// When the Namespace resource is torn down, all the resources in the namespace are torn down,
+// and destroyed once their finalizers are removed. Then the namespace backend is dropped (see
+// WithNamespaceDropper), and the Namespace resource is destroyed once its own finalizers are removed.
+//
+// Namespaces are cleaned up concurrently, each attempt is limited by WithNamespaceCleanupTimeout.
+// Failed cleanups are logged and retried on the next event for the namespace, or after WithNamespaceRetryInterval.
+func (registry *NamespaceRegistry) Run(ctx context.Context) error {
+	ch := make(chan state.Event)
+	retryCh := make(chan resource.Namespace)
+	doneCh := make(chan cleanupResult)
+
+	// namespaces which are being cleaned up
+	running := map[resource.Namespace]struct{}{}
+
+	var wg sync.WaitGroup
+
+	defer wg.Wait()
+
+	if err := registry.state.WatchKind(ctx, resource.NewMetadata(meta.NamespaceName, meta.NamespaceType, "", resource.VersionUndefined), ch, state.WithBootstrapContents(true)); err != nil {
+		return fmt.Errorf("error watching namespaces: %w", err)
+	}
+
+	for {
+		var ns resource.Namespace
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case ns = <-retryCh:
+		case result := <-doneCh:
+			delete(running, result.ns)
+
+			if result.err != nil && ctx.Err() == nil {
+				registry.options.Logger.Printf("error cleaning up namespace %q, retrying in %s: %s", result.ns, registry.options.RetryInterval, result.err)
+
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					select {
+					case <-ctx.Done():
+					case <-time.After(registry.options.RetryInterval):
+						select {
+						case <-ctx.Done():
+						case retryCh <- result.ns:
+						}
+					}
+				}()
+			}
+
+			continue
+		case event := <-ch:
+			if event.Type == state.Errored {
+				registry.options.Logger.Printf("error watching namespaces: %s", event.Error)
+
+				continue
+			}
+
+			if event.Type == state.Destroyed || event.Resource.Metadata().Phase() != resource.PhaseTearingDown {
+				continue
+			}
+
+			ns = event.Resource.Metadata().ID()
+		}
+
+		if ns == meta.NamespaceName {
+			continue
+		}
+
+		if _, ok := running[ns]; ok {
+			continue
+		}
+
+		running[ns] = struct{}{}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			cleanupCtx, cleanupCancel := context.WithTimeout(ctx, registry.options.CleanupTimeout)
+			defer cleanupCancel()
+
+			err := registry.cleanup(cleanupCtx, ns)
+
+			select {
+			case <-ctx.Done():
+			case doneCh <- cleanupResult{ns: ns, err: err}:
+			}
+		}()
+	}
+}
+
+type cleanupResult struct {
+	ns  resource.Namespace
+	err error
+}
+
+// cleanup the namespace if it is still being torn down.
+func (registry *NamespaceRegistry) cleanup(ctx context.Context, ns resource.Namespace) error {
+	namespace, err := registry.state.Get(ctx, namespacePointer(ns))
+	if err != nil {
+		if state.IsNotFoundError(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if namespace.Metadata().Phase() != resource.PhaseTearingDown {
+		return nil
+	}
+
+	return registry.destroy(ctx, ns)
+}
+
+// destroy all the resources in the namespace, and the namespace itself.
+//
+// The namespace is closed for creates until it is destroyed, so it stays closed if destroy fails.
+func (registry *NamespaceRegistry) destroy(ctx context.Context, ns resource.Namespace) error {
+	registry.closeNamespace(ns)
+
+	types, err := registry.namespaceTypes(ctx, ns)
+	if err != nil {
+		return err
+	}
+
+	for _, typ := range types {
+		list, err := registry.state.List(ctx, resource.NewMetadata(ns, typ, "", resource.VersionUndefined))
+		if err != nil {
+			return fmt.Errorf("error listing resources of type %q: %w", typ, err)
+		}
+
+		if err = destroyAll(ctx, registry.state, list.Items); err != nil {
+			return err
+		}
+	}
+
+	if registry.options.Dropper != nil {
+		// resources left in the namespace would be lost without running their finalizers
+		if err = registry.checkEmpty(ctx, ns); err != nil {
+			return fmt.Errorf("error dropping namespace %q: %w", ns, err)
+		}
+
+		registry.options.Dropper.DropNamespace(ns)
+	}
+
+	if err = destroyAll(ctx, registry.state, []resource.Resource{meta.NewNamespace(ns, meta.NamespaceSpec{})}); err != nil {
+		return err
+	}
+
+	registry.openNamespace(ns)
+
+	return nil
+}
+
+// closeNamespace rejects creating resources in the namespace, if the backend supports it.
+func (registry *NamespaceRegistry) closeNamespace(ns resource.Namespace) {
+	if registry.options.Dropper != nil {
+		registry.options.Dropper.CloseNamespace(ns)
+	}
+}
+
+// openNamespace allows creating resources in the namespace closed with closeNamespace.
+func (registry *NamespaceRegistry) openNamespace(ns resource.Namespace) {
+	if registry.options.Dropper != nil {
+		registry.options.Dropper.OpenNamespace(ns)
+	}
+}
+
+// checkEmpty verifies with the state discovery that the namespace has no resources.
+func (registry *NamespaceRegistry) checkEmpty(ctx context.Context, ns resource.Namespace) error {
+	discovery, ok := registry.state.(state.Discovery)
+	if !ok {
+		return fmt.Errorf("state %T doesn't support discovery", registry.state)
+	}
+
+	summaries, err := discovery.Discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, summary := range summaries {
+		if summary.Namespace == ns && len(summary.Types) > 0 {
+			return ErrNamespaceNotEmpty(ns, summary.Types[0].Type)
+		}
+	}
+
+	return nil
+}
+
+// destroyAll tears down the resources, waits for the finalizers to be removed and destroys them.
+func destroyAll(ctx context.Context, st state.State, resources []resource.Resource) error {
+	for _, r := range resources {
+		if _, err := st.Teardown(ctx, r.Metadata()); err != nil && !state.IsNotFoundError(err) {
+			return fmt.Errorf("error tearing down %s: %w", r.Metadata(), err)
+		}
+	}
+
+	for _, r := range resources {
+		if _, err := st.WatchFor(ctx, r.Metadata(), state.WithFinalizerEmpty()); err != nil {
+			return fmt.Errorf("error waiting for finalizers of %s: %w", r.Metadata(), err)
+		}
+
+		if err := st.Destroy(ctx, r.Metadata()); err != nil && !state.IsNotFoundError(err) {
+			return fmt.Errorf("error destroying %s: %w", r.Metadata(), err)
+		}
+	}
+
+	return nil
+}
+
+func namespacePointer(ns resource.Namespace) resource.Pointer {
+	return resource.NewMetadata(meta.NamespaceName, meta.NamespaceType, ns, resource.VersionUndefined)
+}
+
+// toNamespace converts the resource stored in the state into the Namespace.
+func toNamespace(r resource.Resource) (*meta.Namespace, error) {
+	if namespace, ok := r.(*meta.Namespace); ok {
+		return namespace, nil
+	}
+
+	specYAML, err := yaml.Marshal(r.Spec())
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling spec of %s: %w", r, err)
+	}
+
+	namespace := &meta.Namespace{}
+	*namespace.Metadata() = r.Metadata().Copy()
+
+	if err = namespace.UnmarshalSpecYAML(specYAML); err != nil {
+		return nil, fmt.Errorf("error decoding spec of %s: %w", r, err)
+	}
+
+	return namespace, nil
 }
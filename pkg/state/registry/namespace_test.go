@@ -6,10 +6,19 @@ package registry_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/typed"
 	"github.com/talos-systems/os-runtime/pkg/state"
 	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
 	"github.com/talos-systems/os-runtime/pkg/state/impl/namespaced"
@@ -23,3 +32,445 @@ func TestNamespaceRegistry(t *testing.T) {
 
 	assert.NoError(t, r.RegisterDefault(context.Background()))
 }
+
+func TestNamespaceRegistryManagement(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	st := state.WrapCore(namespaced.NewState(inmem.Build))
+
+	resources := registry.NewResourceRegistry(st)
+	require.NoError(t, resources.RegisterDefault(ctx))
+	require.NoError(t, resources.Register(ctx, &typed.Resource[emptySpec, blockDevicesExtension]{}))
+
+	r := registry.NewNamespaceRegistry(st)
+	require.NoError(t, r.RegisterDefault(ctx))
+	require.NoError(t, r.Register(ctx, "user", "user namespace"))
+	require.NoError(t, r.Register(ctx, "system.cosi", "system namespace"))
+
+	for _, name := range []string{"", "User", "user_ns", "user."} {
+		assert.Error(t, r.Register(ctx, name, ""), name)
+	}
+
+	namespaces, err := r.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 3)
+	assert.Equal(t, "meta", namespaces[0].Metadata().ID())
+	assert.Equal(t, "system.cosi", namespaces[1].Metadata().ID())
+	assert.Equal(t, "user", namespaces[2].Metadata().ID())
+
+	require.NoError(t, r.UpdateDescription(ctx, "user", "updated"))
+
+	namespace, err := r.Describe(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, "updated", namespace.TypedSpec().Description)
+
+	_, err = r.Describe(ctx, "unknown")
+	assert.True(t, state.IsNotFoundError(err))
+	assert.EqualError(t, err, `namespace "unknown" is not registered`)
+	assert.True(t, state.IsNotFoundError(r.UpdateDescription(ctx, "unknown", "")))
+	assert.True(t, state.IsNotFoundError(r.Unregister(ctx, "unknown")))
+
+	assert.EqualError(t, r.Unregister(ctx, "meta"), `namespace "meta" can't be unregistered`)
+
+	md := resource.NewMetadata("user", "BlockDevices.test.cosi.dev", "sda", resource.VersionUndefined)
+	md.BumpVersion()
+
+	require.NoError(t, st.Create(ctx, typed.NewResource[emptySpec, blockDevicesExtension](md, emptySpec{})))
+	require.NoError(t, st.AddFinalizer(ctx, md, "A"))
+
+	err = r.Unregister(ctx, "user")
+	assert.True(t, state.IsConflictError(err))
+	assert.EqualError(t, err, `namespace "user" is not empty: it contains resources of type "BlockDevices.test.cosi.dev"`)
+
+	go func() {
+		if _, err := st.WatchFor(ctx, md, state.WithPhases(resource.PhaseTearingDown)); err == nil {
+			st.RemoveFinalizer(ctx, md, "A") //nolint: errcheck
+		}
+	}()
+
+	require.NoError(t, r.Unregister(ctx, "user", registry.WithCascade()))
+
+	_, err = st.Get(ctx, md)
+	assert.True(t, state.IsNotFoundError(err))
+
+	_, err = r.Describe(ctx, "user")
+	assert.True(t, state.IsNotFoundError(err))
+
+	require.NoError(t, r.Unregister(ctx, "system.cosi"))
+}
+
+func TestNamespaceUnregisterUnknownTypes(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	st := state.WrapCore(namespaced.NewState(inmem.Build))
+
+	resources := registry.NewResourceRegistry(st)
+	require.NoError(t, resources.RegisterDefault(ctx))
+
+	r := registry.NewNamespaceRegistry(st)
+	require.NoError(t, r.RegisterDefault(ctx))
+	require.NoError(t, r.Register(ctx, "user", "user namespace"))
+
+	// resource type is not registered, but the resource is found via discovery
+	md := resource.NewMetadata("user", "RawDevices.test.cosi.dev", "sda", resource.VersionUndefined)
+	md.BumpVersion()
+
+	require.NoError(t, st.Create(ctx, typed.NewResource[emptySpec, rawDevicesExtension](md, emptySpec{})))
+
+	err := r.Unregister(ctx, "user")
+	assert.True(t, state.IsConflictError(err))
+	assert.EqualError(t, err, `namespace "user" is not empty: it contains resources of type "RawDevices.test.cosi.dev"`)
+
+	require.NoError(t, st.Destroy(ctx, md))
+	require.NoError(t, r.Unregister(ctx, "user"))
+}
+
+func TestNamespaceTeardown(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	backend := namespaced.NewState(inmem.Build)
+	st := state.WrapCore(backend)
+
+	resources := registry.NewResourceRegistry(st)
+	require.NoError(t, resources.RegisterDefault(ctx))
+	require.NoError(t, resources.Register(ctx, &typed.Resource[emptySpec, blockDevicesExtension]{}))
+
+	r := registry.NewNamespaceRegistry(st, registry.WithNamespaceDropper(backend))
+	require.NoError(t, r.RegisterDefault(ctx))
+	require.NoError(t, r.Register(ctx, "user", "user namespace"))
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- r.Run(ctx)
+	}()
+
+	var mds []resource.Metadata
+
+	for _, id := range []resource.ID{"sda", "sdb"} {
+		md := resource.NewMetadata("user", "BlockDevices.test.cosi.dev", id, resource.VersionUndefined)
+		md.BumpVersion()
+
+		require.NoError(t, st.Create(ctx, typed.NewResource[emptySpec, blockDevicesExtension](md, emptySpec{})))
+
+		mds = append(mds, md)
+	}
+
+	// resource type is not registered, but the resource is found via discovery
+	raw := resource.NewMetadata("user", "RawDevices.test.cosi.dev", "sda", resource.VersionUndefined)
+	raw.BumpVersion()
+
+	require.NoError(t, st.Create(ctx, typed.NewResource[emptySpec, rawDevicesExtension](raw, emptySpec{})))
+
+	mds = append(mds, raw)
+
+	require.NoError(t, st.AddFinalizer(ctx, mds[0], "A"))
+	require.NoError(t, st.AddFinalizer(ctx, raw, "B"))
+
+	namespace, err := r.Describe(ctx, "user")
+	require.NoError(t, err)
+
+	_, err = st.Teardown(ctx, namespace.Metadata())
+	require.NoError(t, err)
+
+	for _, pending := range []struct {
+		md        resource.Metadata
+		finalizer resource.Finalizer
+	}{
+		{mds[0], "A"},
+		{raw, "B"},
+	} {
+		_, err = st.WatchFor(ctx, pending.md, state.WithPhases(resource.PhaseTearingDown))
+		require.NoError(t, err)
+
+		// namespace is not destroyed while finalizers are pending
+		_, err = r.Describe(ctx, "user")
+		require.NoError(t, err)
+
+		require.NoError(t, st.RemoveFinalizer(ctx, pending.md, pending.finalizer))
+	}
+
+	_, err = st.WatchFor(ctx, namespace.Metadata(), state.WithEventTypes(state.Destroyed))
+	require.NoError(t, err)
+
+	for _, md := range mds {
+		_, err = st.Get(ctx, md)
+		assert.True(t, state.IsNotFoundError(err))
+	}
+
+	cancel()
+
+	assert.NoError(t, <-errCh)
+}
+
+// flakyDiscovery fails the discovery while failing is set.
+type flakyDiscovery struct {
+	state.CoreState
+
+	failing int32
+}
+
+func (st *flakyDiscovery) Discover(ctx context.Context) ([]state.NamespaceSummary, error) {
+	if atomic.LoadInt32(&st.failing) != 0 {
+		return nil, errors.New("discovery failed")
+	}
+
+	return st.CoreState.(state.Discovery).Discover(ctx)
+}
+
+func TestNamespaceTeardownRetry(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	backend := namespaced.NewState(inmem.Build)
+	core := &flakyDiscovery{CoreState: backend, failing: 1}
+	st := state.WrapCore(core)
+
+	resources := registry.NewResourceRegistry(st)
+	require.NoError(t, resources.RegisterDefault(ctx))
+
+	r := registry.NewNamespaceRegistry(st,
+		registry.WithNamespaceDropper(backend),
+		registry.WithNamespaceRetryInterval(10*time.Millisecond),
+		registry.WithNamespaceLogger(log.New(io.Discard, "", 0)),
+	)
+	require.NoError(t, r.RegisterDefault(ctx))
+
+	var namespaces []resource.Resource
+
+	for _, ns := range []resource.Namespace{"a", "b"} {
+		require.NoError(t, r.Register(ctx, ns, ""))
+
+		namespace, err := r.Describe(ctx, ns)
+		require.NoError(t, err)
+
+		_, err = st.Teardown(ctx, namespace.Metadata())
+		require.NoError(t, err)
+
+		namespaces = append(namespaces, namespace)
+	}
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- r.Run(ctx)
+	}()
+
+	// namespaces are not dropped while the discovery fails, and the cleanup is retried
+	time.Sleep(50 * time.Millisecond)
+
+	for _, namespace := range namespaces {
+		_, err := st.Get(ctx, namespace.Metadata())
+		require.NoError(t, err)
+	}
+
+	atomic.StoreInt32(&core.failing, 0)
+
+	for _, namespace := range namespaces {
+		_, err := st.WatchFor(ctx, namespace.Metadata(), state.WithEventTypes(state.Destroyed))
+		require.NoError(t, err)
+	}
+
+	cancel()
+
+	assert.NoError(t, <-errCh)
+}
+
+func TestNamespaceTeardownConcurrentCreate(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	backend := namespaced.NewState(inmem.Build)
+	st := state.WrapCore(backend)
+
+	resources := registry.NewResourceRegistry(st)
+	require.NoError(t, resources.RegisterDefault(ctx))
+	require.NoError(t, resources.Register(ctx, &typed.Resource[emptySpec, blockDevicesExtension]{}))
+
+	r := registry.NewNamespaceRegistry(st, registry.WithNamespaceDropper(backend))
+	require.NoError(t, r.RegisterDefault(ctx))
+	require.NoError(t, r.Register(ctx, "user", "user namespace"))
+
+	kind := resource.NewMetadata("user", "BlockDevices.test.cosi.dev", "", resource.VersionUndefined)
+
+	watchCh := make(chan state.Event)
+	require.NoError(t, st.WatchKind(ctx, kind, watchCh))
+
+	destroyedCh := make(chan resource.ID, 10000)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-watchCh:
+				if event.Type == state.Destroyed {
+					destroyedCh <- event.Resource.Metadata().ID()
+				}
+			}
+		}
+	}()
+
+	// resource with the pending finalizer keeps the namespace in teardown
+	stuck := resource.NewMetadata("user", "BlockDevices.test.cosi.dev", "stuck", resource.VersionUndefined)
+	stuck.BumpVersion()
+
+	require.NoError(t, st.Create(ctx, typed.NewResource[emptySpec, blockDevicesExtension](stuck, emptySpec{})))
+	require.NoError(t, st.AddFinalizer(ctx, stuck, "A"))
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- r.Run(ctx)
+	}()
+
+	createdCh := make(chan []resource.ID, 1)
+	createErrCh := make(chan error, 1)
+
+	go func() {
+		var created []resource.ID
+
+		defer func() {
+			createdCh <- created
+		}()
+
+		for i := 0; ; i++ {
+			md := resource.NewMetadata("user", "BlockDevices.test.cosi.dev", fmt.Sprintf("dev%d", i), resource.VersionUndefined)
+			md.BumpVersion()
+
+			if err := st.Create(ctx, typed.NewResource[emptySpec, blockDevicesExtension](md, emptySpec{})); err != nil {
+				createErrCh <- err
+
+				return
+			}
+
+			created = append(created, md.ID())
+
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	namespace, err := r.Describe(ctx, "user")
+	require.NoError(t, err)
+
+	_, err = st.Teardown(ctx, namespace.Metadata())
+	require.NoError(t, err)
+
+	// creates are rejected while the namespace is torn down
+	err = <-createErrCh
+	assert.True(t, state.IsConflictError(err))
+	assert.Contains(t, err.Error(), `namespace "user" is being torn down`)
+
+	created := <-createdCh
+
+	_, err = st.WatchFor(ctx, stuck, state.WithPhases(resource.PhaseTearingDown))
+	require.NoError(t, err)
+
+	require.NoError(t, st.RemoveFinalizer(ctx, stuck, "A"))
+
+	_, err = st.WatchFor(ctx, namespace.Metadata(), state.WithEventTypes(state.Destroyed))
+	require.NoError(t, err)
+
+	// every created resource is destroyed rather than dropped with the namespace
+	pending := map[resource.ID]struct{}{stuck.ID(): {}}
+
+	for _, id := range created {
+		pending[id] = struct{}{}
+	}
+
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("resources were not destroyed: %v", pending)
+		case id := <-destroyedCh:
+			delete(pending, id)
+		}
+	}
+
+	// namespace can be registered again
+	require.NoError(t, r.Register(ctx, "user", "user namespace"))
+	require.NoError(t, st.Create(ctx, typed.NewResource[emptySpec, blockDevicesExtension](stuck, emptySpec{})))
+
+	cancel()
+
+	assert.NoError(t, <-errCh)
+}
+
+func TestNamespaceTeardownStuck(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	backend := namespaced.NewState(inmem.Build)
+	st := state.WrapCore(backend)
+
+	resources := registry.NewResourceRegistry(st)
+	require.NoError(t, resources.RegisterDefault(ctx))
+	require.NoError(t, resources.Register(ctx, &typed.Resource[emptySpec, blockDevicesExtension]{}))
+
+	r := registry.NewNamespaceRegistry(st,
+		registry.WithNamespaceDropper(backend),
+		registry.WithNamespaceRetryInterval(10*time.Millisecond),
+		registry.WithNamespaceCleanupTimeout(50*time.Millisecond),
+		registry.WithNamespaceLogger(log.New(io.Discard, "", 0)),
+	)
+	require.NoError(t, r.RegisterDefault(ctx))
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- r.Run(ctx)
+	}()
+
+	// finalizer of the resource in the namespace "a" is not removed until the namespace "b" is destroyed
+	stuck := resource.NewMetadata("a", "BlockDevices.test.cosi.dev", "sda", resource.VersionUndefined)
+	stuck.BumpVersion()
+
+	var namespaces []resource.Resource
+
+	for _, ns := range []resource.Namespace{"a", "b"} {
+		require.NoError(t, r.Register(ctx, ns, ""))
+
+		if ns == "a" {
+			require.NoError(t, st.Create(ctx, typed.NewResource[emptySpec, blockDevicesExtension](stuck, emptySpec{})))
+			require.NoError(t, st.AddFinalizer(ctx, stuck, "A"))
+		}
+
+		namespace, err := r.Describe(ctx, ns)
+		require.NoError(t, err)
+
+		_, err = st.Teardown(ctx, namespace.Metadata())
+		require.NoError(t, err)
+
+		namespaces = append(namespaces, namespace)
+	}
+
+	_, err := st.WatchFor(ctx, namespaces[1].Metadata(), state.WithEventTypes(state.Destroyed))
+	require.NoError(t, err)
+
+	_, err = r.Describe(ctx, "a")
+	require.NoError(t, err)
+
+	require.NoError(t, st.RemoveFinalizer(ctx, stuck, "A"))
+
+	_, err = st.WatchFor(ctx, namespaces[0].Metadata(), state.WithEventTypes(state.Destroyed))
+	require.NoError(t, err)
+
+	cancel()
+
+	assert.NoError(t, <-errCh)
+}
@@ -0,0 +1,126 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/state"
+)
+
+// names returns all the (lowercase) names the resource definition can be referred by.
+func names(definition *meta.ResourceDefinitionSpec) []string {
+	result := []string{definition.ID()}
+
+	if definition.DisplayType != "" {
+		result = append(result, strings.ToLower(definition.DisplayType))
+	}
+
+	for _, alias := range definition.Aliases {
+		result = append(result, strings.ToLower(alias))
+	}
+
+	return result
+}
+
+// definitionSpec extracts the spec from the ResourceDefinition stored in the state.
+//
+// Definitions might be stored either as typed resources or as resource.Any.
+func definitionSpec(r resource.Resource) (meta.ResourceDefinitionSpec, error) {
+	switch rd := r.(type) {
+	case *meta.ResourceDefinition:
+		return rd.TypedSpec().DeepCopy(), nil
+	default:
+		var definition meta.ResourceDefinitionSpec
+
+		specYAML, err := yaml.Marshal(r.Spec())
+		if err != nil {
+			return definition, fmt.Errorf("error marshaling spec of %s: %w", r, err)
+		}
+
+		if err = yaml.Unmarshal(specYAML, &definition); err != nil {
+			return definition, fmt.Errorf("error decoding spec of %s: %w", r, err)
+		}
+
+		return definition, nil
+	}
+}
+
+func listDefinitions(ctx context.Context, st state.State) ([]meta.ResourceDefinitionSpec, error) {
+	list, err := st.List(ctx, resource.NewMetadata(meta.NamespaceName, meta.ResourceDefinitionType, "", resource.VersionUndefined))
+	if err != nil {
+		return nil, fmt.Errorf("error listing resource definitions: %w", err)
+	}
+
+	result := make([]meta.ResourceDefinitionSpec, 0, len(list.Items))
+
+	for _, item := range list.Items {
+		definition, err := definitionSpec(item)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, definition)
+	}
+
+	return result, nil
+}
+
+// Resolve a user-typed resource type name into the canonical resource definition.
+//
+// Name might be the full resource type, any of the aliases, singular or plural
+// form of the type, matching is case-insensitive.
+func (registry *ResourceRegistry) Resolve(ctx context.Context, name string) (meta.ResourceDefinitionSpec, error) {
+	definitions, err := listDefinitions(ctx, registry.state)
+	if err != nil {
+		return meta.ResourceDefinitionSpec{}, err
+	}
+
+	lowered := strings.ToLower(name)
+
+	for i := range definitions {
+		for _, n := range names(&definitions[i]) {
+			if n == lowered {
+				return definitions[i], nil
+			}
+		}
+	}
+
+	return meta.ResourceDefinitionSpec{}, ErrTypeNotFound(name)
+}
+
+// checkAliases verifies that the names of the definition don't collide with names of other registered definitions.
+func (registry *ResourceRegistry) checkAliases(ctx context.Context, definition *meta.ResourceDefinitionSpec) error {
+	definitions, err := listDefinitions(ctx, registry.state)
+	if err != nil {
+		return err
+	}
+
+	taken := map[string]resource.Type{}
+
+	for i := range definitions {
+		if definitions[i].Type == definition.Type {
+			continue
+		}
+
+		for _, n := range names(&definitions[i]) {
+			taken[n] = definitions[i].Type
+		}
+	}
+
+	for _, n := range names(definition) {
+		if existing, ok := taken[n]; ok {
+			return ErrAliasConflict(n, definition.Type, existing)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package registry_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/resource/typed"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/namespaced"
+	"github.com/talos-systems/os-runtime/pkg/state/registry"
+)
+
+type emptySpec struct{}
+
+func (emptySpec) DeepCopy() emptySpec { return emptySpec{} }
+
+type rawDevicesExtension struct{}
+
+func (rawDevicesExtension) ResourceDefinition() meta.ResourceDefinitionSpec {
+	return meta.ResourceDefinitionSpec{
+		Type: "RawDevices.test.cosi.dev",
+	}
+}
+
+type blockDevicesExtension struct{}
+
+func (blockDevicesExtension) ResourceDefinition() meta.ResourceDefinitionSpec {
+	return meta.ResourceDefinitionSpec{
+		Type:    "BlockDevices.test.cosi.dev",
+		Aliases: []resource.Type{"dev"},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	r := registry.NewResourceRegistry(state.WrapCore(namespaced.NewState(inmem.Build)))
+
+	require.NoError(t, r.RegisterDefault(ctx))
+	require.NoError(t, r.Register(ctx, typed.NewResource[emptySpec, blockDevicesExtension](resource.NewMetadata("default", "BlockDevices.test.cosi.dev", "", resource.VersionUndefined), emptySpec{})))
+
+	for _, name := range []string{"Namespaces.meta.cosi.dev", "namespaces.meta.cosi.dev", "ns", "namespace", "Namespaces", "namespaces.meta"} {
+		definition, err := r.Resolve(ctx, name)
+		require.NoError(t, err, name)
+		assert.Equal(t, meta.NamespaceType, definition.Type, name)
+	}
+
+	for _, name := range []string{"rd", "ResourceDefinition", "resourcedefinitions"} {
+		definition, err := r.Resolve(ctx, name)
+		require.NoError(t, err, name)
+		assert.Equal(t, meta.ResourceDefinitionType, definition.Type, name)
+	}
+
+	definition, err := r.Resolve(ctx, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, "BlockDevice", definition.DisplayType)
+
+	_, err = r.Resolve(ctx, "unknown")
+	assert.True(t, state.IsNotFoundError(err))
+	assert.EqualError(t, err, `resource type "unknown" is not registered`)
+
+	// "rd" is already taken by ResourceDefinitions
+	err = r.Register(ctx, typed.NewResource[emptySpec, rawDevicesExtension](resource.NewMetadata("default", "RawDevices.test.cosi.dev", "", resource.VersionUndefined), emptySpec{}))
+	assert.True(t, state.IsConflictError(err))
+	assert.EqualError(t, err, `error registering resource RawDevices.test.cosi.dev(""): alias "rd" of resource type "RawDevices.test.cosi.dev" conflicts with resource type "ResourceDefinitions.meta.cosi.dev"`)
+
+	_, err = r.Resolve(ctx, "rawdevices")
+	assert.True(t, state.IsNotFoundError(err))
+}
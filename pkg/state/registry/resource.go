@@ -7,6 +7,8 @@ package registry
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sync"
 
 	"github.com/talos-systems/os-runtime/pkg/resource"
 	"github.com/talos-systems/os-runtime/pkg/resource/meta"
@@ -15,16 +17,26 @@ import (
 
 // ResourceRegistry facilitates tracking namespaces.
 type ResourceRegistry struct {
+	// serializes registration to make alias checks consistent
+	mu sync.Mutex
+
 	state state.State
+	types *TypeRegistry
 }
 
 // NewResourceRegistry creates new ResourceRegistry.
 func NewResourceRegistry(state state.State) *ResourceRegistry {
 	return &ResourceRegistry{
 		state: state,
+		types: NewTypeRegistry(),
 	}
 }
 
+// Types returns the registry of Go types for the registered resources.
+func (registry *ResourceRegistry) Types() *TypeRegistry {
+	return registry.types
+}
+
 // RegisterDefault registers default resource definitions.
 func (registry *ResourceRegistry) RegisterDefault(ctx context.Context) error {
 	for _, r := range []resource.Resource{&meta.ResourceDefinition{}, &meta.Namespace{}} {
@@ -36,8 +48,17 @@ func (registry *ResourceRegistry) RegisterDefault(ctx context.Context) error {
 	return nil
 }
 
-// Register a namespace.
+// Register a resource definition.
+//
+// If the resource definition is already registered, it is updated if the change is compatible:
+// aliases and versions can't be removed and the storage version can't be changed while there are
+// resources of that type.
+// Registration fails if any of the aliases of the resource collides with the aliases of registered resources.
+// Go type of the resource is registered in the type registry.
 func (registry *ResourceRegistry) Register(ctx context.Context, r resource.Resource) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
 	definitionProvider, ok := r.(meta.ResourceDefinitionProvider)
 	if !ok {
 		return fmt.Errorf("value %v doesn't implement core.ResourceDefinitionProvider", r)
@@ -45,10 +66,219 @@ func (registry *ResourceRegistry) Register(ctx context.Context, r resource.Resou
 
 	definition := definitionProvider.ResourceDefinition()
 
-	r, err := meta.NewResourceDefinition(definition)
+	rd, err := meta.NewResourceDefinition(definition)
+	if err != nil {
+		return fmt.Errorf("error registering resource %s: %w", r, err)
+	}
+
+	if err = registry.checkAliases(ctx, rd.TypedSpec()); err != nil {
+		return fmt.Errorf("error registering resource %s: %w", r, err)
+	}
+
+	current, err := registry.state.Get(ctx, rd.Metadata())
+	if err != nil && !state.IsNotFoundError(err) {
+		return err
+	}
+
+	exists := err == nil
+	changed := !exists
+
+	if exists {
+		currentDefinition, err := definitionSpec(current)
+		if err != nil {
+			return err
+		}
+
+		if !reflect.DeepEqual(currentDefinition, *rd.TypedSpec()) {
+			if err = registry.checkCompatibility(ctx, &currentDefinition, rd.TypedSpec()); err != nil {
+				return fmt.Errorf("error registering resource %s: %w", r, err)
+			}
+
+			changed = true
+		}
+	}
+
+	// Go type is registered before the definition is persisted, so that persisted definitions always have the Go type
+	revert, err := registry.registerType(*rd.TypedSpec(), FactoryFor(r))
 	if err != nil {
 		return fmt.Errorf("error registering resource %s: %w", r, err)
 	}
 
-	return registry.state.Create(ctx, r)
+	if !changed {
+		return nil
+	}
+
+	if exists {
+		_, err = registry.state.Apply(ctx, rd)
+	} else {
+		err = registry.state.Create(ctx, rd)
+	}
+
+	if err != nil {
+		revert()
+
+		return err
+	}
+
+	return nil
+}
+
+// registerType registers the Go type of the resource, or updates its definition.
+//
+// registerType returns a function which reverts the change.
+func (registry *ResourceRegistry) registerType(definition meta.ResourceDefinitionSpec, factory Factory) (func(), error) {
+	previous, registered := registry.types.entry(definition.Type)
+	if !registered {
+		if err := registry.types.Register(definition, factory); err != nil {
+			return nil, err
+		}
+
+		return func() {
+			registry.types.Unregister(definition.Type)
+		}, nil
+	}
+
+	if reflect.DeepEqual(previous.definition, definition) {
+		return func() {}, nil
+	}
+
+	if err := registry.types.Update(definition, factory); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		registry.types.restore(previous)
+	}, nil
+}
+
+// Unregister a resource definition by type or alias.
+//
+// Unregister fails if there are resources of that type in any registered namespace.
+func (registry *ResourceRegistry) Unregister(ctx context.Context, name string) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	definition, err := registry.Resolve(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if definition.Type == meta.ResourceDefinitionType || definition.Type == meta.NamespaceType {
+		return fmt.Errorf("resource type %q can't be unregistered", definition.Type)
+	}
+
+	ns, err := registry.findResources(ctx, &definition)
+	if err != nil {
+		return err
+	}
+
+	if ns != "" {
+		return ErrTypeInUse(definition.Type, ns)
+	}
+
+	if err = registry.state.Destroy(ctx, resource.NewMetadata(meta.NamespaceName, meta.ResourceDefinitionType, definition.ID(), resource.VersionUndefined)); err != nil {
+		return err
+	}
+
+	registry.types.Unregister(definition.Type)
+
+	return nil
+}
+
+// checkCompatibility verifies that the definition update doesn't break existing resources.
+func (registry *ResourceRegistry) checkCompatibility(ctx context.Context, current, updated *meta.ResourceDefinitionSpec) error {
+	var reason string
+
+	switch {
+	case len(missing(current.Aliases, updated.Aliases)) > 0:
+		reason = fmt.Sprintf("aliases %q are removed", missing(current.Aliases, updated.Aliases))
+	case len(missing(current.Versions, updated.Versions)) > 0:
+		reason = fmt.Sprintf("versions %q are removed", missing(current.Versions, updated.Versions))
+	case current.StorageVersion != updated.StorageVersion:
+		reason = fmt.Sprintf("storage version is changed from %q to %q", current.StorageVersion, updated.StorageVersion)
+	default:
+		return nil
+	}
+
+	ns, err := registry.findResources(ctx, current)
+	if err != nil {
+		return err
+	}
+
+	if ns == "" {
+		return nil
+	}
+
+	return ErrIncompatibleDefinition(current.Type, reason, ns)
+}
+
+// findResources returns the first namespace which contains resources of the type.
+//
+// Namespaces are found via the state discovery, so that resources in the namespaces which are not registered
+// are found as well. If the state doesn't support discovery, registered namespaces and the default namespace are checked.
+func (registry *ResourceRegistry) findResources(ctx context.Context, definition *meta.ResourceDefinitionSpec) (resource.Namespace, error) {
+	if discovery, ok := registry.state.(state.Discovery); ok {
+		if summaries, err := discovery.Discover(ctx); err == nil {
+			for _, summary := range summaries {
+				for _, typeSummary := range summary.Types {
+					if typeSummary.Type == definition.Type {
+						return summary.Namespace, nil
+					}
+				}
+			}
+
+			return "", nil
+		}
+	}
+
+	list, err := registry.state.List(ctx, resource.NewMetadata(meta.NamespaceName, meta.NamespaceType, "", resource.VersionUndefined))
+	if err != nil {
+		return "", fmt.Errorf("error listing namespaces: %w", err)
+	}
+
+	namespaces := make([]resource.Namespace, 0, len(list.Items)+1)
+
+	for _, item := range list.Items {
+		namespaces = append(namespaces, item.Metadata().ID())
+	}
+
+	if definition.DefaultNamespace != "" {
+		namespaces = append(namespaces, definition.DefaultNamespace)
+	}
+
+	for _, ns := range namespaces {
+		items, err := registry.state.List(ctx, resource.NewMetadata(ns, definition.Type, "", resource.VersionUndefined))
+		if err != nil {
+			return "", fmt.Errorf("error listing resources of type %q: %w", definition.Type, err)
+		}
+
+		if len(items.Items) > 0 {
+			return ns, nil
+		}
+	}
+
+	return "", nil
+}
+
+// missing returns items of a which are not in b.
+func missing[T comparable](a, b []T) []T {
+	var result []T
+
+	for _, x := range a {
+		found := false
+
+		for _, y := range b {
+			if x == y {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, x)
+		}
+	}
+
+	return result
 }
@@ -6,10 +6,16 @@ package registry_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/resource/typed"
 	"github.com/talos-systems/os-runtime/pkg/state"
 	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
 	"github.com/talos-systems/os-runtime/pkg/state/impl/namespaced"
@@ -23,3 +29,150 @@ func TestResourceRegistry(t *testing.T) {
 
 	assert.NoError(t, r.RegisterDefault(context.Background()))
 }
+
+const widgetType = resource.Type("Widgets.test.cosi.dev")
+
+type widgetExtension struct{}
+
+func (widgetExtension) ResourceDefinition() meta.ResourceDefinitionSpec {
+	return meta.ResourceDefinitionSpec{
+		Type:             widgetType,
+		Aliases:          []resource.Type{"wdg"},
+		DefaultNamespace: "default",
+	}
+}
+
+type widgetExtensionV2 struct{}
+
+func (widgetExtensionV2) ResourceDefinition() meta.ResourceDefinitionSpec {
+	return meta.ResourceDefinitionSpec{
+		Type:             widgetType,
+		Aliases:          []resource.Type{"wdg", "wg"},
+		DefaultNamespace: "default",
+	}
+}
+
+type widgetExtensionV3 struct{}
+
+func (widgetExtensionV3) ResourceDefinition() meta.ResourceDefinitionSpec {
+	return meta.ResourceDefinitionSpec{
+		Type:             widgetType,
+		DefaultNamespace: "default",
+	}
+}
+
+func TestResourceRegistryUpdate(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	st := state.WrapCore(namespaced.NewState(inmem.Build))
+	r := registry.NewResourceRegistry(st)
+
+	require.NoError(t, r.RegisterDefault(ctx))
+	require.NoError(t, r.RegisterDefault(ctx))
+
+	watchCh := make(chan state.Event)
+	require.NoError(t, st.WatchKind(ctx, resource.NewMetadata(meta.NamespaceName, meta.ResourceDefinitionType, "", resource.VersionUndefined), watchCh))
+
+	expectEvent := func(eventType state.EventType, aliases ...resource.Type) {
+		select {
+		case event := <-watchCh:
+			assert.Equal(t, eventType, event.Type)
+
+			definition := event.Resource.(*meta.ResourceDefinition).TypedSpec()
+			assert.Equal(t, widgetType, definition.Type)
+
+			for _, alias := range aliases {
+				assert.Contains(t, definition.Aliases, alias)
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for event")
+		}
+	}
+
+	require.NoError(t, r.Register(ctx, &typed.Resource[emptySpec, widgetExtension]{}))
+	expectEvent(state.Created, "wdg")
+
+	// re-registering is a no-op
+	require.NoError(t, r.Register(ctx, &typed.Resource[emptySpec, widgetExtension]{}))
+
+	require.NoError(t, r.Register(ctx, &typed.Resource[emptySpec, widgetExtensionV2]{}))
+	expectEvent(state.Updated, "wdg", "wg")
+
+	res, ok := r.Types().New(widgetType)
+	require.True(t, ok)
+	assert.IsType(t, &typed.Resource[emptySpec, widgetExtensionV2]{}, res)
+
+	md := resource.NewMetadata("default", widgetType, "w1", resource.VersionUndefined)
+	md.BumpVersion()
+	require.NoError(t, st.Create(ctx, typed.NewResource[emptySpec, widgetExtensionV2](md, emptySpec{})))
+
+	err := r.Register(ctx, &typed.Resource[emptySpec, widgetExtensionV3]{})
+	assert.True(t, state.IsConflictError(err))
+	assert.Contains(t, err.Error(), `incompatible update of resource type "Widgets.test.cosi.dev": aliases ["wdg" "wg"] are removed, while namespace "default" contains resources of that type`)
+
+	err = r.Unregister(ctx, "wg")
+	assert.True(t, state.IsConflictError(err))
+	assert.EqualError(t, err, `resource type "Widgets.test.cosi.dev" is in use: namespace "default" contains resources of that type`)
+
+	assert.EqualError(t, r.Unregister(ctx, "ns"), `resource type "Namespaces.meta.cosi.dev" can't be unregistered`)
+
+	require.NoError(t, st.Destroy(ctx, md))
+
+	require.NoError(t, r.Unregister(ctx, "wg"))
+	expectEvent(state.Destroyed)
+
+	_, ok = r.Types().Definition(widgetType)
+	assert.False(t, ok)
+
+	_, err = r.Resolve(ctx, "wdg")
+	assert.True(t, state.IsNotFoundError(err))
+
+	assert.True(t, state.IsNotFoundError(r.Unregister(ctx, "wg")))
+}
+
+type failingCreateState struct {
+	state.CoreState
+}
+
+func (failingCreateState) Create(context.Context, resource.Resource, ...state.CreateOption) error {
+	return errors.New("create failed")
+}
+
+func TestResourceRegistryRollback(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	r := registry.NewResourceRegistry(state.WrapCore(failingCreateState{namespaced.NewState(inmem.Build)}))
+
+	assert.EqualError(t, r.Register(ctx, &typed.Resource[emptySpec, widgetExtension]{}), "create failed")
+
+	// type is not registered if the definition was not persisted
+	_, ok := r.Types().Definition(widgetType)
+	assert.False(t, ok)
+}
+
+func TestResourceRegistryUnregisteredNamespace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	st := state.WrapCore(namespaced.NewState(inmem.Build))
+	r := registry.NewResourceRegistry(st)
+
+	require.NoError(t, r.RegisterDefault(ctx))
+	require.NoError(t, r.Register(ctx, &typed.Resource[emptySpec, widgetExtension]{}))
+
+	// namespace "orphan" is not registered, but the resource is found via discovery
+	md := resource.NewMetadata("orphan", widgetType, "w1", resource.VersionUndefined)
+	md.BumpVersion()
+	require.NoError(t, st.Create(ctx, typed.NewResource[emptySpec, widgetExtension](md, emptySpec{})))
+
+	err := r.Unregister(ctx, "wdg")
+	assert.True(t, state.IsConflictError(err))
+	assert.EqualError(t, err, `resource type "Widgets.test.cosi.dev" is in use: namespace "orphan" contains resources of that type`)
+
+	require.NoError(t, st.Destroy(ctx, md))
+	require.NoError(t, r.Unregister(ctx, "wdg"))
+}
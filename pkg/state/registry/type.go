@@ -0,0 +1,251 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+)
+
+// Factory creates an empty resource of the specific Go type.
+type Factory func() resource.Resource
+
+// FactoryFor builds a Factory which creates zero values of the same Go type as r.
+func FactoryFor(r resource.Resource) Factory {
+	typ := reflect.TypeOf(r)
+
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+
+		return func() resource.Resource {
+			return reflect.New(typ).Interface().(resource.Resource) //nolint: errcheck
+		}
+	}
+
+	return func() resource.Resource {
+		return reflect.Zero(typ).Interface().(resource.Resource) //nolint: errcheck
+	}
+}
+
+type typeEntry struct {
+	definition meta.ResourceDefinitionSpec
+	factory    Factory
+
+	// versioned resource types only
+	versions    map[string]Factory
+	goTypes     map[reflect.Type]string
+	conversions map[conversionKey]ConversionFunc
+}
+
+// TypeRegistry maps resource types to Go types.
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	types map[resource.Type]typeEntry
+}
+
+// NewTypeRegistry creates new TypeRegistry.
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		types: map[resource.Type]typeEntry{},
+	}
+}
+
+// Register a factory for the resource type described by the definition.
+//
+// For versioned resource types, factory creates resources of the storage version.
+func (registry *TypeRegistry) Register(definition meta.ResourceDefinitionSpec, factory Factory) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, exists := registry.types[definition.Type]; exists {
+		return fmt.Errorf("resource type %q is already registered", definition.Type)
+	}
+
+	entry := typeEntry{
+		definition: definition.DeepCopy(),
+		factory:    factory,
+	}
+
+	if definition.StorageVersion != "" {
+		entry.versions = map[string]Factory{
+			definition.StorageVersion: factory,
+		}
+		entry.goTypes = map[reflect.Type]string{
+			reflect.TypeOf(factory()): definition.StorageVersion,
+		}
+		entry.conversions = map[conversionKey]ConversionFunc{}
+	}
+
+	registry.types[definition.Type] = entry
+
+	return nil
+}
+
+// Update the definition of the registered resource type.
+//
+// Versions and conversions registered for the resource type are preserved.
+func (registry *TypeRegistry) Update(definition meta.ResourceDefinitionSpec, factory Factory) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	current, ok := registry.types[definition.Type]
+	if !ok {
+		return fmt.Errorf("resource type %q is not registered", definition.Type)
+	}
+
+	// maps are copied, as the previous entry might be restored
+	entry := typeEntry{
+		definition: definition.DeepCopy(),
+		factory:    factory,
+	}
+
+	if definition.StorageVersion != "" {
+		entry.versions = make(map[string]Factory, len(current.versions)+1)
+		entry.goTypes = make(map[reflect.Type]string, len(current.goTypes)+1)
+		entry.conversions = make(map[conversionKey]ConversionFunc, len(current.conversions))
+
+		for version, factory := range current.versions {
+			entry.versions[version] = factory
+		}
+
+		// previous Go type of the storage version is kept, as there might be resources of that type
+		for goType, version := range current.goTypes {
+			entry.goTypes[goType] = version
+		}
+
+		for key, conversion := range current.conversions {
+			entry.conversions[key] = conversion
+		}
+
+		entry.versions[definition.StorageVersion] = factory
+		entry.goTypes[reflect.TypeOf(factory())] = definition.StorageVersion
+	}
+
+	registry.types[definition.Type] = entry
+
+	return nil
+}
+
+// Unregister the resource type.
+func (registry *TypeRegistry) Unregister(typ resource.Type) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	delete(registry.types, typ)
+}
+
+func (registry *TypeRegistry) entry(typ resource.Type) (typeEntry, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	entry, ok := registry.types[typ]
+
+	return entry, ok
+}
+
+func (registry *TypeRegistry) restore(entry typeEntry) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.types[entry.definition.Type] = entry
+}
+
+// Types returns the list of registered resource types.
+func (registry *TypeRegistry) Types() []resource.Type {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	types := make([]resource.Type, 0, len(registry.types))
+
+	for typ := range registry.types {
+		types = append(types, typ)
+	}
+
+	sort.Strings(types)
+
+	return types
+}
+
+// Definition returns the definition of the registered resource type.
+func (registry *TypeRegistry) Definition(typ resource.Type) (meta.ResourceDefinitionSpec, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	entry, ok := registry.types[typ]
+
+	return entry.definition.DeepCopy(), ok
+}
+
+// New creates an empty resource for the registered resource type.
+//
+// New implements manifest.Registry interface.
+func (registry *TypeRegistry) New(typ resource.Type) (resource.Resource, bool) {
+	registry.mu.RLock()
+	entry, ok := registry.types[typ]
+	registry.mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	return entry.factory(), true
+}
+
+// FromAny converts resource.Any into the registered typed resource.
+//
+// If the type is not registered, Any is returned as is.
+// Typed resources should implement resource.SpecUnmarshaler.
+func (registry *TypeRegistry) FromAny(any *resource.Any) (resource.Resource, error) {
+	res, ok := registry.New(any.Metadata().Type())
+	if !ok {
+		return any, nil
+	}
+
+	unmarshaler, ok := res.(resource.SpecUnmarshaler)
+	if !ok {
+		return nil, fmt.Errorf("resource %s doesn't support spec unmarshaling", res)
+	}
+
+	specYAML, err := yaml.Marshal(any.Spec())
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling spec of %s: %w", any, err)
+	}
+
+	*res.Metadata() = any.Metadata().Copy()
+
+	if err = unmarshaler.UnmarshalSpecYAML(specYAML); err != nil {
+		return nil, fmt.Errorf("error decoding spec of %s: %w", any, err)
+	}
+
+	return res, nil
+}
+
+// ToAny converts any resource into resource.Any.
+func ToAny(r resource.Resource) (*resource.Any, error) {
+	if any, ok := r.(*resource.Any); ok {
+		return any, nil
+	}
+
+	specYAML, err := yaml.Marshal(r.Spec())
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling spec of %s: %w", r, err)
+	}
+
+	any := &resource.Any{}
+	*any.Metadata() = r.Metadata().Copy()
+
+	if err = any.UnmarshalSpecYAML(specYAML); err != nil {
+		return nil, fmt.Errorf("error decoding spec of %s: %w", r, err)
+	}
+
+	return any, nil
+}
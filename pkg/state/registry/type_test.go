@@ -0,0 +1,82 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package registry_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/manifest"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/namespaced"
+	"github.com/talos-systems/os-runtime/pkg/state/registry"
+)
+
+func TestTypeRegistry(t *testing.T) {
+	t.Parallel()
+
+	r := registry.NewResourceRegistry(state.WrapCore(namespaced.NewState(inmem.Build)))
+
+	require.NoError(t, r.RegisterDefault(context.Background()))
+
+	types := r.Types()
+
+	assert.Equal(t, []resource.Type{meta.NamespaceType, meta.ResourceDefinitionType}, types.Types())
+
+	definition, ok := types.Definition(meta.NamespaceType)
+	require.True(t, ok)
+	assert.Equal(t, "Namespace", definition.DisplayType)
+
+	res, ok := types.New(meta.NamespaceType)
+	require.True(t, ok)
+	assert.IsType(t, &meta.Namespace{}, res)
+
+	_, ok = types.New("Unknowns.cosi.dev")
+	assert.False(t, ok)
+
+	assert.EqualError(t, types.Register(definition, registry.FactoryFor(res)), `resource type "Namespaces.meta.cosi.dev" is already registered`)
+
+	ns := meta.NewNamespace("user", meta.NamespaceSpec{
+		Description: "user namespace",
+	})
+	ns.Metadata().Finalizers().Add("A")
+
+	any, err := registry.ToAny(ns)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{"description": "user namespace"}, any.Value())
+	assert.True(t, ns.Metadata().Equal(*any.Metadata()))
+
+	converted, err := types.FromAny(any)
+	require.NoError(t, err)
+
+	assert.True(t, resource.Equal(ns, converted))
+
+	other := &resource.Any{}
+	*other.Metadata() = resource.NewMetadata("default", "Unknowns.cosi.dev", "a", resource.VersionUndefined)
+
+	converted, err = types.FromAny(other)
+	require.NoError(t, err)
+	assert.Same(t, other, converted)
+
+	resources, err := manifest.ReadAll(strings.NewReader(`
+metadata:
+    namespace: meta
+    type: Namespaces.meta.cosi.dev
+    id: user
+spec:
+    description: foo
+`), manifest.WithRegistry(types))
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.IsType(t, &meta.Namespace{}, resources[0])
+}
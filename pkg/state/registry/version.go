@@ -0,0 +1,196 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package registry
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+)
+
+// ConversionFunc converts a resource to another spec version.
+//
+// Metadata of the source resource is copied to the result of the conversion.
+type ConversionFunc func(resource.Resource) (resource.Resource, error)
+
+type conversionKey struct {
+	from, to string
+}
+
+func (registry *TypeRegistry) versionedEntry(typ resource.Type) (typeEntry, error) {
+	entry, ok := registry.types[typ]
+	if !ok {
+		return entry, fmt.Errorf("resource type %q is not registered", typ)
+	}
+
+	if entry.versions == nil {
+		return entry, fmt.Errorf("resource type %q is not versioned", typ)
+	}
+
+	return entry, nil
+}
+
+// RegisterVersion registers a factory for a non-storage version of the resource type.
+func (registry *TypeRegistry) RegisterVersion(typ resource.Type, version string, factory Factory) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	entry, err := registry.versionedEntry(typ)
+	if err != nil {
+		return err
+	}
+
+	if !entry.definition.HasVersion(version) {
+		return fmt.Errorf("version %q is not declared for resource type %q", version, typ)
+	}
+
+	if _, exists := entry.versions[version]; exists {
+		return fmt.Errorf("version %q of resource type %q is already registered", version, typ)
+	}
+
+	goType := reflect.TypeOf(factory())
+
+	if existing, exists := entry.goTypes[goType]; exists {
+		return fmt.Errorf("%s is already registered for version %q of resource type %q", goType, existing, typ)
+	}
+
+	entry.versions[version] = factory
+	entry.goTypes[goType] = version
+
+	return nil
+}
+
+// RegisterConversion registers a function which converts the resource from one spec version to another.
+//
+// Conversions which are not registered directly are performed via the storage version.
+func (registry *TypeRegistry) RegisterConversion(typ resource.Type, from, to string, conversion ConversionFunc) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	entry, err := registry.versionedEntry(typ)
+	if err != nil {
+		return err
+	}
+
+	for _, version := range []string{from, to} {
+		if !entry.definition.HasVersion(version) {
+			return fmt.Errorf("version %q is not declared for resource type %q", version, typ)
+		}
+	}
+
+	key := conversionKey{from: from, to: to}
+
+	if _, exists := entry.conversions[key]; exists {
+		return fmt.Errorf("conversion from %q to %q for resource type %q is already registered", from, to, typ)
+	}
+
+	entry.conversions[key] = conversion
+
+	return nil
+}
+
+// NewVersion creates an empty resource of the specific version of the resource type.
+func (registry *TypeRegistry) NewVersion(typ resource.Type, version string) (resource.Resource, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	factory, ok := registry.types[typ].versions[version]
+	if !ok {
+		return nil, false
+	}
+
+	return factory(), true
+}
+
+// VersionOf returns the spec version of the resource based on its Go type.
+//
+// For resource types without versions, empty version is returned.
+func (registry *TypeRegistry) VersionOf(r resource.Resource) (string, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	return registry.versionOf(r)
+}
+
+func (registry *TypeRegistry) versionOf(r resource.Resource) (string, error) {
+	entry, ok := registry.types[r.Metadata().Type()]
+	if !ok || entry.versions == nil {
+		return "", nil
+	}
+
+	version, ok := entry.goTypes[reflect.TypeOf(r)]
+	if !ok {
+		return "", fmt.Errorf("%T is not registered as a version of resource type %q", r, r.Metadata().Type())
+	}
+
+	return version, nil
+}
+
+// Convert the resource to the specified spec version.
+//
+// If the resource is already of the requested version, it's returned as is.
+func (registry *TypeRegistry) Convert(r resource.Resource, version string) (resource.Resource, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	from, err := registry.versionOf(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if from == version {
+		return r, nil
+	}
+
+	entry, err := registry.versionedEntry(r.Metadata().Type())
+	if err != nil {
+		return nil, err
+	}
+
+	if !entry.definition.HasVersion(version) {
+		return nil, fmt.Errorf("version %q is not declared for resource type %q", version, r.Metadata().Type())
+	}
+
+	if conversion, ok := entry.conversions[conversionKey{from: from, to: version}]; ok {
+		return registry.convert(r, conversion, version)
+	}
+
+	storage := entry.definition.StorageVersion
+
+	toStorage, ok1 := entry.conversions[conversionKey{from: from, to: storage}]
+	fromStorage, ok2 := entry.conversions[conversionKey{from: storage, to: version}]
+
+	if !ok1 || !ok2 || from == storage || version == storage {
+		return nil, fmt.Errorf("no conversion from %q to %q for resource type %q", from, version, r.Metadata().Type())
+	}
+
+	converted, err := registry.convert(r, toStorage, storage)
+	if err != nil {
+		return nil, err
+	}
+
+	return registry.convert(converted, fromStorage, version)
+}
+
+func (registry *TypeRegistry) convert(r resource.Resource, conversion ConversionFunc, version string) (resource.Resource, error) {
+	converted, err := conversion(r)
+	if err != nil {
+		return nil, fmt.Errorf("error converting %s to version %q: %w", r, version, err)
+	}
+
+	*converted.Metadata() = r.Metadata().Copy()
+
+	actual, err := registry.versionOf(converted)
+	if err != nil {
+		return nil, fmt.Errorf("error converting %s to version %q: %w", r, version, err)
+	}
+
+	if actual != version {
+		return nil, fmt.Errorf("error converting %s to version %q: conversion produced version %q", r, version, actual)
+	}
+
+	return converted, nil
+}
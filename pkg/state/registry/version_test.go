@@ -0,0 +1,222 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package registry_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/resource/typed"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/namespaced"
+	"github.com/talos-systems/os-runtime/pkg/state/registry"
+)
+
+const gadgetType = resource.Type("Gadgets.test.cosi.dev")
+
+type gadgetV1Alpha1Spec struct {
+	Name string `yaml:"name"`
+}
+
+func (spec gadgetV1Alpha1Spec) DeepCopy() gadgetV1Alpha1Spec { return spec }
+
+type gadgetV1Spec struct {
+	FirstName string `yaml:"firstName"`
+	LastName  string `yaml:"lastName"`
+}
+
+func (spec gadgetV1Spec) DeepCopy() gadgetV1Spec { return spec }
+
+type gadgetExtension struct{}
+
+func (gadgetExtension) ResourceDefinition() meta.ResourceDefinitionSpec {
+	return meta.ResourceDefinitionSpec{
+		Type:           gadgetType,
+		Versions:       []string{"v1alpha1", "v1"},
+		StorageVersion: "v1",
+	}
+}
+
+type (
+	gadgetV1Alpha1 = typed.Resource[gadgetV1Alpha1Spec, gadgetExtension]
+	gadgetV1       = typed.Resource[gadgetV1Spec, gadgetExtension]
+)
+
+func newGadgetV1Alpha1(id resource.ID, name string) *gadgetV1Alpha1 {
+	md := resource.NewMetadata("default", gadgetType, id, resource.VersionUndefined)
+	md.BumpVersion()
+
+	return typed.NewResource[gadgetV1Alpha1Spec, gadgetExtension](md, gadgetV1Alpha1Spec{Name: name})
+}
+
+func registerGadgets(ctx context.Context, t *testing.T, r *registry.ResourceRegistry) {
+	require.NoError(t, r.Register(ctx, &gadgetV1{}))
+
+	types := r.Types()
+
+	require.NoError(t, types.RegisterVersion(gadgetType, "v1alpha1", registry.FactoryFor(&gadgetV1Alpha1{})))
+
+	require.NoError(t, types.RegisterConversion(gadgetType, "v1alpha1", "v1", func(r resource.Resource) (resource.Resource, error) {
+		first, last, _ := strings.Cut(r.(*gadgetV1Alpha1).TypedSpec().Name, " ")
+
+		return typed.NewResource[gadgetV1Spec, gadgetExtension](resource.Metadata{}, gadgetV1Spec{FirstName: first, LastName: last}), nil
+	}))
+
+	require.NoError(t, types.RegisterConversion(gadgetType, "v1", "v1alpha1", func(r resource.Resource) (resource.Resource, error) {
+		spec := r.(*gadgetV1).TypedSpec()
+
+		return typed.NewResource[gadgetV1Alpha1Spec, gadgetExtension](resource.Metadata{}, gadgetV1Alpha1Spec{Name: spec.FirstName + " " + spec.LastName}), nil
+	}))
+}
+
+func TestVersionRegistration(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	r := registry.NewResourceRegistry(state.WrapCore(namespaced.NewState(inmem.Build)))
+
+	require.NoError(t, r.RegisterDefault(ctx))
+	registerGadgets(ctx, t, r)
+
+	types := r.Types()
+
+	assert.EqualError(t, types.RegisterVersion(gadgetType, "v2", registry.FactoryFor(&gadgetV1Alpha1{})), `version "v2" is not declared for resource type "Gadgets.test.cosi.dev"`)
+	assert.EqualError(t, types.RegisterVersion(gadgetType, "v1alpha1", registry.FactoryFor(&gadgetV1Alpha1{})), `version "v1alpha1" of resource type "Gadgets.test.cosi.dev" is already registered`)
+	assert.EqualError(t, types.RegisterVersion(meta.NamespaceType, "v1", registry.FactoryFor(&meta.Namespace{})), `resource type "Namespaces.meta.cosi.dev" is not versioned`)
+	assert.EqualError(t, types.RegisterConversion(gadgetType, "v1", "v1alpha1", nil), `conversion from "v1" to "v1alpha1" for resource type "Gadgets.test.cosi.dev" is already registered`)
+
+	version, err := types.VersionOf(newGadgetV1Alpha1("a", "John Doe"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1alpha1", version)
+
+	version, err = types.VersionOf(meta.NewNamespace("user", meta.NamespaceSpec{}))
+	require.NoError(t, err)
+	assert.Empty(t, version)
+
+	res, ok := types.NewVersion(gadgetType, "v1alpha1")
+	require.True(t, ok)
+	assert.IsType(t, &gadgetV1Alpha1{}, res)
+
+	converted, err := types.Convert(newGadgetV1Alpha1("a", "John Doe"), "v1")
+	require.NoError(t, err)
+	assert.Equal(t, gadgetV1Spec{FirstName: "John", LastName: "Doe"}, *converted.(*gadgetV1).TypedSpec())
+	assert.Equal(t, resource.ID("a"), converted.Metadata().ID())
+
+	_, err = types.Convert(newGadgetV1Alpha1("a", "John Doe"), "v2")
+	assert.EqualError(t, err, `version "v2" is not declared for resource type "Gadgets.test.cosi.dev"`)
+}
+
+type gadgetExtensionV2 struct{}
+
+func (gadgetExtensionV2) ResourceDefinition() meta.ResourceDefinitionSpec {
+	definition := gadgetExtension{}.ResourceDefinition()
+	definition.Aliases = []resource.Type{"gdg"}
+
+	return definition
+}
+
+func TestVersionRegistrationUpdate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	r := registry.NewResourceRegistry(state.WrapCore(namespaced.NewState(inmem.Build)))
+
+	require.NoError(t, r.RegisterDefault(ctx))
+	registerGadgets(ctx, t, r)
+
+	// versions and conversions survive the definition update
+	require.NoError(t, r.Register(ctx, &typed.Resource[gadgetV1Spec, gadgetExtensionV2]{}))
+
+	types := r.Types()
+
+	definition, ok := types.Definition(gadgetType)
+	require.True(t, ok)
+	assert.Contains(t, definition.Aliases, resource.Type("gdg"))
+
+	res, ok := types.NewVersion(gadgetType, "v1alpha1")
+	require.True(t, ok)
+	assert.IsType(t, &gadgetV1Alpha1{}, res)
+
+	res, ok = types.New(gadgetType)
+	require.True(t, ok)
+	assert.IsType(t, &typed.Resource[gadgetV1Spec, gadgetExtensionV2]{}, res)
+
+	converted, err := types.Convert(newGadgetV1Alpha1("a", "John Doe"), "v1")
+	require.NoError(t, err)
+	assert.Equal(t, gadgetV1Spec{FirstName: "John", LastName: "Doe"}, *converted.(*gadgetV1).TypedSpec())
+}
+
+func TestVersionedState(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	core := namespaced.NewState(inmem.Build)
+	r := registry.NewResourceRegistry(state.WrapCore(core))
+
+	require.NoError(t, r.RegisterDefault(ctx))
+	registerGadgets(ctx, t, r)
+
+	storage := state.WrapCore(registry.NewVersionedState(core, r.Types()))
+	old := state.WrapCore(registry.NewVersionedState(core, r.Types(), registry.WithSpecVersion(gadgetType, "v1alpha1")))
+
+	watchCh := make(chan state.Event)
+	require.NoError(t, old.WatchKind(ctx, resource.NewMetadata("default", gadgetType, "", resource.VersionUndefined), watchCh))
+
+	require.NoError(t, old.Create(ctx, newGadgetV1Alpha1("a", "John Doe")))
+
+	select {
+	case event := <-watchCh:
+		assert.Equal(t, state.Created, event.Type)
+		assert.Equal(t, "John Doe", event.Resource.(*gadgetV1Alpha1).TypedSpec().Name)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for event")
+	}
+
+	stored, err := storage.Get(ctx, resource.NewMetadata("default", gadgetType, "a", resource.VersionUndefined))
+	require.NoError(t, err)
+	assert.Equal(t, gadgetV1Spec{FirstName: "John", LastName: "Doe"}, *stored.(*gadgetV1).TypedSpec())
+
+	_, err = storage.UpdateWithConflicts(ctx, stored.Metadata(), func(r resource.Resource) error {
+		r.(*gadgetV1).TypedSpec().LastName = "Smith"
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	list, err := old.List(ctx, resource.NewMetadata("default", gadgetType, "", resource.VersionUndefined))
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "John Smith", list.Items[0].(*gadgetV1Alpha1).TypedSpec().Name)
+	assert.Equal(t, "2", list.Items[0].Metadata().Version().String())
+
+	select {
+	case event := <-watchCh:
+		assert.Equal(t, state.Updated, event.Type)
+		assert.Equal(t, "John Smith", event.Resource.(*gadgetV1Alpha1).TypedSpec().Name)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for event")
+	}
+
+	_, err = old.UpdateWithConflicts(ctx, stored.Metadata(), func(r resource.Resource) error {
+		r.(*gadgetV1Alpha1).TypedSpec().Name = "Jane Smith"
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	stored, err = storage.Get(ctx, stored.Metadata())
+	require.NoError(t, err)
+	assert.Equal(t, gadgetV1Spec{FirstName: "Jane", LastName: "Smith"}, *stored.(*gadgetV1).TypedSpec())
+}
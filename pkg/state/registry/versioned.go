@@ -0,0 +1,208 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/state"
+)
+
+// VersionedStateOptions configure VersionedState.
+type VersionedStateOptions struct {
+	// Versions maps resource type to the spec version returned on reads.
+	Versions map[resource.Type]string
+}
+
+// VersionedStateOption applies settings to VersionedStateOptions.
+type VersionedStateOption func(*VersionedStateOptions)
+
+// WithSpecVersion requests resources of the type to be returned in the specified spec version.
+//
+// By default resources are returned in the storage version.
+func WithSpecVersion(typ resource.Type, version string) VersionedStateOption {
+	return func(opts *VersionedStateOptions) {
+		if opts.Versions == nil {
+			opts.Versions = map[resource.Type]string{}
+		}
+
+		opts.Versions[typ] = version
+	}
+}
+
+// VersionedState converts versioned resources between spec versions on top of CoreState.
+//
+// Resources are converted to the storage version on writes, and to the requested
+// version on reads.
+type VersionedState struct {
+	state state.CoreState
+	types *TypeRegistry
+
+	options VersionedStateOptions
+}
+
+// NewVersionedState creates new VersionedState.
+func NewVersionedState(st state.CoreState, types *TypeRegistry, opts ...VersionedStateOption) *VersionedState {
+	options := VersionedStateOptions{}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &VersionedState{
+		state:   st,
+		types:   types,
+		options: options,
+	}
+}
+
+func (st *VersionedState) toStorage(r resource.Resource) (resource.Resource, error) {
+	definition, ok := st.types.Definition(r.Metadata().Type())
+	if !ok || definition.StorageVersion == "" {
+		return r, nil
+	}
+
+	return st.types.Convert(r, definition.StorageVersion)
+}
+
+func (st *VersionedState) requested(typ resource.Type) (string, bool) {
+	version, ok := st.options.Versions[typ]
+
+	return version, ok
+}
+
+func (st *VersionedState) fromStorage(r resource.Resource) (resource.Resource, error) {
+	version, ok := st.requested(r.Metadata().Type())
+	if !ok {
+		return r, nil
+	}
+
+	return st.types.Convert(r, version)
+}
+
+// Get a resource by type and ID.
+func (st *VersionedState) Get(ctx context.Context, ptr resource.Pointer, opts ...state.GetOption) (resource.Resource, error) {
+	r, err := st.state.Get(ctx, ptr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return st.fromStorage(r)
+}
+
+// List resources by kind.
+func (st *VersionedState) List(ctx context.Context, kind resource.Kind, opts ...state.ListOption) (resource.List, error) {
+	list, err := st.state.List(ctx, kind, opts...)
+	if err != nil {
+		return list, err
+	}
+
+	for i := range list.Items {
+		if list.Items[i], err = st.fromStorage(list.Items[i]); err != nil {
+			return resource.List{}, err
+		}
+	}
+
+	return list, nil
+}
+
+// Create a resource converting it to the storage version.
+func (st *VersionedState) Create(ctx context.Context, r resource.Resource, opts ...state.CreateOption) error {
+	stored, err := st.toStorage(r)
+	if err != nil {
+		return err
+	}
+
+	return st.state.Create(ctx, stored, opts...)
+}
+
+// Update a resource converting it to the storage version.
+func (st *VersionedState) Update(ctx context.Context, curVersion resource.Version, newResource resource.Resource, opts ...state.UpdateOption) error {
+	stored, err := st.toStorage(newResource)
+	if err != nil {
+		return err
+	}
+
+	return st.state.Update(ctx, curVersion, stored, opts...)
+}
+
+// Destroy a resource.
+func (st *VersionedState) Destroy(ctx context.Context, ptr resource.Pointer, opts ...state.DestroyOption) error {
+	return st.state.Destroy(ctx, ptr, opts...)
+}
+
+// Discover implements state.Discovery if the underlying state supports it.
+func (st *VersionedState) Discover(ctx context.Context) ([]state.NamespaceSummary, error) {
+	discovery, ok := st.state.(state.Discovery)
+	if !ok {
+		return nil, fmt.Errorf("state %T doesn't support discovery", st.state)
+	}
+
+	return discovery.Discover(ctx)
+}
+
+// Watch state of a resource by type.
+//
+// Events which can't be converted to the requested version are delivered in the storage version.
+func (st *VersionedState) Watch(ctx context.Context, ptr resource.Pointer, ch chan<- state.Event, opts ...state.WatchOption) error {
+	if _, ok := st.requested(ptr.Type()); !ok {
+		return st.state.Watch(ctx, ptr, ch, opts...)
+	}
+
+	inner := make(chan state.Event)
+
+	if err := st.state.Watch(ctx, ptr, inner, opts...); err != nil {
+		return err
+	}
+
+	go st.convertEvents(ctx, inner, ch)
+
+	return nil
+}
+
+// WatchKind watches resources of specific kind (namespace and type).
+//
+// Events which can't be converted to the requested version are delivered in the storage version.
+func (st *VersionedState) WatchKind(ctx context.Context, kind resource.Kind, ch chan<- state.Event, opts ...state.WatchKindOption) error {
+	if _, ok := st.requested(kind.Type()); !ok {
+		return st.state.WatchKind(ctx, kind, ch, opts...)
+	}
+
+	inner := make(chan state.Event)
+
+	if err := st.state.WatchKind(ctx, kind, inner, opts...); err != nil {
+		return err
+	}
+
+	go st.convertEvents(ctx, inner, ch)
+
+	return nil
+}
+
+func (st *VersionedState) convertEvents(ctx context.Context, in <-chan state.Event, out chan<- state.Event) {
+	for {
+		var event state.Event
+
+		select {
+		case <-ctx.Done():
+			return
+		case event = <-in:
+		}
+
+		if event.Resource != nil {
+			if converted, err := st.fromStorage(event.Resource); err == nil {
+				event.Resource = converted
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case out <- event:
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"context"
 
 	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/patch"
 )
 
 // EventType is a type of StateEvent related to resource change.
@@ -22,16 +23,24 @@ const (
 	Updated
 	// Resource was destroyed.
 	Destroyed
+	// Resource change can't be delivered.
+	//
+	// Resource is a tombstone of the affected resource, Error describes the failure.
+	// The watch keeps running, but the watcher's view of the resource is stale until it is re-read.
+	Errored
 )
 
 func (eventType EventType) String() string {
-	return [...]string{"Created", "Updated", "Destroyed"}[eventType]
+	return [...]string{"Created", "Updated", "Destroyed", "Errored"}[eventType]
 }
 
 // Event is emitted when resource changes.
 type Event struct {
 	Type     EventType
 	Resource resource.Resource
+
+	// Error is set for Errored events.
+	Error error
 }
 
 // CoreState is the central broker in the system handling state and changes.
@@ -89,6 +98,19 @@ type State interface {
 	// UpdateWithConflicts automatically handles conflicts on update.
 	UpdateWithConflicts(context.Context, resource.Pointer, UpdaterFunc) (resource.Resource, error)
 
+	// Apply creates the resource if it doesn't exist, or updates it otherwise.
+	//
+	// Apply is not atomic: it is built on top of Get, Create and Update, and retries
+	// if the resource is changed concurrently (see WithApplyOnlyIfUnchanged).
+	// Apply returns the resource as it was stored.
+	Apply(context.Context, resource.Resource, ...ApplyOption) (resource.Resource, error)
+
+	// Patch applies a partial update to the resource spec handling conflicts.
+	//
+	// Resource should implement resource.SpecUnmarshaler.
+	// Patch returns the resource as it was stored.
+	Patch(context.Context, resource.Pointer, patch.Patch) (resource.Resource, error)
+
 	// WatchFor watches for resource to reach all of the specified conditions.
 	WatchFor(context.Context, resource.Pointer, ...WatchForConditionFunc) (resource.Resource, error)
 
@@ -0,0 +1,194 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+)
+
+// TypedEvent is emitted when resource of the specific Go type changes.
+type TypedEvent[T resource.Resource] struct {
+	Type EventType
+
+	// Resource is the zero value if the event carries no resource (e.g. a tombstone),
+	// or if the resource has unexpected Go type.
+	Resource T
+
+	// Metadata of the resource, always set.
+	Metadata resource.Metadata
+
+	// Error is set if the resource has unexpected Go type, or for Errored events.
+	Error error
+}
+
+// TypedState wraps State providing access to the resources of a specific Go type.
+//
+// If the stored resource has a different Go type, an error compatible with ErrUnexpectedType is returned.
+type TypedState[T resource.Resource] struct {
+	state State
+}
+
+// NewTypedState creates new TypedState.
+func NewTypedState[T resource.Resource](st State) *TypedState[T] {
+	return &TypedState[T]{
+		state: st,
+	}
+}
+
+func (st *TypedState[T]) convert(r resource.Resource) (T, error) {
+	typed, ok := r.(T)
+	if !ok {
+		var zero T
+
+		return zero, eUnexpectedType{
+			fmt.Errorf("resource %s has unexpected type %T, expected %T", r.Metadata(), r, zero),
+		}
+	}
+
+	return typed, nil
+}
+
+// Get a resource by type and ID.
+func (st *TypedState[T]) Get(ctx context.Context, ptr resource.Pointer, opts ...GetOption) (T, error) {
+	r, err := st.state.Get(ctx, ptr, opts...)
+	if err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	return st.convert(r)
+}
+
+// List resources by kind.
+func (st *TypedState[T]) List(ctx context.Context, kind resource.Kind, opts ...ListOption) ([]T, error) {
+	list, err := st.state.List(ctx, kind, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]T, 0, len(list.Items))
+
+	for _, item := range list.Items {
+		typed, err := st.convert(item)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, typed)
+	}
+
+	return result, nil
+}
+
+// Create a resource.
+func (st *TypedState[T]) Create(ctx context.Context, r T, opts ...CreateOption) error {
+	return st.state.Create(ctx, r, opts...)
+}
+
+// Destroy a resource.
+func (st *TypedState[T]) Destroy(ctx context.Context, ptr resource.Pointer, opts ...DestroyOption) error {
+	return st.state.Destroy(ctx, ptr, opts...)
+}
+
+// UpdateWithConflicts automatically handles conflicts on update.
+//
+// UpdateWithConflicts returns the updated resource.
+func (st *TypedState[T]) UpdateWithConflicts(ctx context.Context, ptr resource.Pointer, f func(T) error) (T, error) {
+	var updated T
+
+	_, err := st.state.UpdateWithConflicts(ctx, ptr, func(r resource.Resource) error {
+		typed, err := st.convert(r)
+		if err != nil {
+			return err
+		}
+
+		updated = typed
+
+		return f(typed)
+	})
+	if err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	return updated, nil
+}
+
+// WatchFor watches for resource to reach all of the specified conditions.
+//
+// If the condition matches a resource which doesn't exist, zero value is returned.
+func (st *TypedState[T]) WatchFor(ctx context.Context, ptr resource.Pointer, conditionFunc ...WatchForConditionFunc) (T, error) {
+	var zero T
+
+	r, err := st.state.WatchFor(ctx, ptr, conditionFunc...)
+	if err != nil {
+		return zero, err
+	}
+
+	if _, tombstone := r.(*resource.Tombstone); tombstone {
+		return zero, nil
+	}
+
+	return st.convert(r)
+}
+
+// Watch state of a resource.
+func (st *TypedState[T]) Watch(ctx context.Context, ptr resource.Pointer, ch chan<- TypedEvent[T], opts ...WatchOption) error {
+	inner := make(chan Event)
+
+	if err := st.state.Watch(ctx, ptr, inner, opts...); err != nil {
+		return err
+	}
+
+	go st.convertEvents(ctx, inner, ch)
+
+	return nil
+}
+
+// WatchKind watches resources of specific kind (namespace and type).
+func (st *TypedState[T]) WatchKind(ctx context.Context, kind resource.Kind, ch chan<- TypedEvent[T], opts ...WatchKindOption) error {
+	inner := make(chan Event)
+
+	if err := st.state.WatchKind(ctx, kind, inner, opts...); err != nil {
+		return err
+	}
+
+	go st.convertEvents(ctx, inner, ch)
+
+	return nil
+}
+
+func (st *TypedState[T]) convertEvents(ctx context.Context, in <-chan Event, out chan<- TypedEvent[T]) {
+	for {
+		var event Event
+
+		select {
+		case <-ctx.Done():
+			return
+		case event = <-in:
+		}
+
+		typedEvent := TypedEvent[T]{
+			Type:     event.Type,
+			Metadata: event.Resource.Metadata().Copy(),
+			Error:    event.Error,
+		}
+
+		if _, tombstone := event.Resource.(*resource.Tombstone); !tombstone {
+			typedEvent.Resource, typedEvent.Error = st.convert(event.Resource)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case out <- typedEvent:
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package state_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/state"
+	"github.com/talos-systems/os-runtime/pkg/state/conformance"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
+	"github.com/talos-systems/os-runtime/pkg/state/impl/namespaced"
+)
+
+func TestTypedState(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	st := state.WrapCore(namespaced.NewState(inmem.Build))
+	namespaces := state.NewTypedState[*meta.Namespace](st)
+
+	kind := resource.NewMetadata(meta.NamespaceName, meta.NamespaceType, "", resource.VersionUndefined)
+
+	ch := make(chan state.TypedEvent[*meta.Namespace])
+
+	require.NoError(t, namespaces.WatchKind(ctx, kind, ch))
+
+	require.NoError(t, namespaces.Create(ctx, meta.NewNamespace("user", meta.NamespaceSpec{Description: "foo"})))
+
+	event := <-ch
+	assert.Equal(t, state.Created, event.Type)
+	require.NoError(t, event.Error)
+	assert.Equal(t, "foo", event.Resource.TypedSpec().Description)
+
+	ns, err := namespaces.Get(ctx, meta.NewNamespace("user", meta.NamespaceSpec{}).Metadata())
+	require.NoError(t, err)
+	assert.Equal(t, "foo", ns.TypedSpec().Description)
+
+	ns, err = namespaces.UpdateWithConflicts(ctx, ns.Metadata(), func(ns *meta.Namespace) error {
+		ns.TypedSpec().Description = "bar"
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "bar", ns.TypedSpec().Description)
+
+	event = <-ch
+	assert.Equal(t, state.Updated, event.Type)
+	assert.Equal(t, "bar", event.Resource.TypedSpec().Description)
+
+	list, err := namespaces.List(ctx, kind)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "bar", list[0].TypedSpec().Description)
+
+	// resource of the same type, but different Go type
+	md := resource.NewMetadata(meta.NamespaceName, meta.NamespaceType, "path", resource.VersionUndefined)
+	path := conformance.NewPathResource(md.Namespace(), md.ID())
+	*path.Metadata() = md
+	path.Metadata().BumpVersion()
+
+	require.NoError(t, st.Create(ctx, path))
+
+	event = <-ch
+	assert.Equal(t, state.Created, event.Type)
+	assert.True(t, state.IsUnexpectedTypeError(event.Error))
+	assert.Nil(t, event.Resource)
+	assert.Equal(t, "path", event.Metadata.ID())
+
+	_, err = namespaces.Get(ctx, path.Metadata())
+	assert.True(t, state.IsUnexpectedTypeError(err))
+
+	_, err = namespaces.List(ctx, kind)
+	assert.True(t, state.IsUnexpectedTypeError(err))
+
+	_, err = namespaces.UpdateWithConflicts(ctx, path.Metadata(), func(*meta.Namespace) error {
+		return nil
+	})
+	assert.True(t, state.IsUnexpectedTypeError(err))
+
+	require.NoError(t, namespaces.Destroy(ctx, ns.Metadata()))
+
+	event = <-ch
+	assert.Equal(t, state.Destroyed, event.Type)
+	assert.Equal(t, "user", event.Metadata.ID())
+
+	ns, err = namespaces.WatchFor(ctx, ns.Metadata(), state.WithEventTypes(state.Destroyed))
+	require.NoError(t, err)
+	assert.Nil(t, ns)
+}
@@ -6,8 +6,10 @@ package state
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/patch"
 )
 
 // WrapCore converts CoreState to State.
@@ -56,6 +58,125 @@ func (state coreWrapper) UpdateWithConflicts(ctx context.Context, resourcePointe
 	}
 }
 
+// Apply creates the resource if it doesn't exist, or updates it otherwise.
+//
+// Apply is a client-side Get followed by Create or Update, it is not atomic. If the resource is
+// created, updated or destroyed between these calls, the whole sequence is retried,
+// so the updater might be called several times.
+func (state coreWrapper) Apply(ctx context.Context, res resource.Resource, opts ...ApplyOption) (resource.Resource, error) {
+	var options ApplyOptions
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	for {
+		current, err := state.Get(ctx, res.Metadata())
+		if err != nil && !IsNotFoundError(err) {
+			return nil, err
+		}
+
+		var stored resource.Resource
+
+		if err != nil {
+			stored, err = state.applyCreate(ctx, res, &options)
+		} else {
+			stored, err = state.applyUpdate(ctx, current, res, &options)
+		}
+
+		if err == nil {
+			return stored, nil
+		}
+
+		// resource got created or destroyed concurrently, or it was updated since it was read
+		if (IsConflictError(err) || IsNotFoundError(err)) && !options.OnlyIfUnchanged {
+			continue
+		}
+
+		return nil, err
+	}
+}
+
+func (state coreWrapper) applyCreate(ctx context.Context, res resource.Resource, options *ApplyOptions) (resource.Resource, error) {
+	// resource is created with the version as passed, same as with Create
+	newResource := res.DeepCopy()
+
+	if options.Updater != nil {
+		if err := options.Updater(newResource); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := state.Create(ctx, newResource); err != nil {
+		return nil, err
+	}
+
+	return newResource, nil
+}
+
+func (state coreWrapper) applyUpdate(ctx context.Context, current, res resource.Resource, options *ApplyOptions) (resource.Resource, error) {
+	curVersion := current.Metadata().Version()
+
+	if options.OnlyIfUnchanged && !curVersion.Equal(res.Metadata().Version()) {
+		return nil, eConflict{
+			fmt.Errorf("resource %s apply conflict: expected version %q, actual version %q", current.Metadata(), res.Metadata().Version(), curVersion),
+		}
+	}
+
+	var newResource resource.Resource
+
+	if options.Updater != nil {
+		newResource = current.DeepCopy()
+
+		if err := options.Updater(newResource); err != nil {
+			return nil, err
+		}
+	} else {
+		newResource = res.DeepCopy()
+
+		if !options.Force {
+			newResource.Metadata().SetPhase(current.Metadata().Phase())
+			*newResource.Metadata().Finalizers() = append(resource.Finalizers(nil), *current.Metadata().Finalizers()...)
+		}
+	}
+
+	newResource.Metadata().SetVersion(curVersion)
+
+	if resource.Equal(current, newResource) {
+		return current, nil
+	}
+
+	newResource.Metadata().BumpVersion()
+
+	var updateOpts []UpdateOption
+
+	if options.Force {
+		updateOpts = append(updateOpts, WithForcePhaseTransition(), WithForceFinalizerRemoval())
+	}
+
+	if err := state.Update(ctx, curVersion, newResource, updateOpts...); err != nil {
+		return nil, err
+	}
+
+	return newResource, nil
+}
+
+// Patch applies a partial update to the resource spec handling conflicts.
+func (state coreWrapper) Patch(ctx context.Context, resourcePointer resource.Pointer, p patch.Patch) (resource.Resource, error) {
+	var patched resource.Resource
+
+	_, err := state.UpdateWithConflicts(ctx, resourcePointer, func(r resource.Resource) error {
+		patched = r
+
+		return patch.Apply(r, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
+
 // WatchFor watches for resource to reach all of the specified conditions.
 func (state coreWrapper) WatchFor(ctx context.Context, pointer resource.Pointer, conditionFunc ...WatchForConditionFunc) (resource.Resource, error) {
 	var condition WatchForCondition
@@ -142,3 +263,13 @@ func (state coreWrapper) RemoveFinalizer(ctx context.Context, resourcePointer re
 
 	return err
 }
+
+// Discover implements Discovery if the wrapped CoreState supports it.
+func (state coreWrapper) Discover(ctx context.Context) ([]NamespaceSummary, error) {
+	discovery, ok := state.CoreState.(Discovery)
+	if !ok {
+		return nil, fmt.Errorf("state %T doesn't support discovery", state.CoreState)
+	}
+
+	return discovery.Discover(ctx)
+}
@@ -5,11 +5,16 @@
 package state_test
 
 import (
+	"context"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/talos-systems/os-runtime/pkg/resource"
+	"github.com/talos-systems/os-runtime/pkg/resource/meta"
+	"github.com/talos-systems/os-runtime/pkg/resource/patch"
 	"github.com/talos-systems/os-runtime/pkg/state"
 	"github.com/talos-systems/os-runtime/pkg/state/conformance"
 	"github.com/talos-systems/os-runtime/pkg/state/impl/inmem"
@@ -24,3 +29,33 @@ func TestWrapConformance(t *testing.T) {
 		Namespaces: []resource.Namespace{"default", "controller", "system", "runtime"},
 	})
 }
+
+func TestPatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	st := state.WrapCore(namespaced.NewState(inmem.Build))
+
+	ns := meta.NewNamespace("user", meta.NamespaceSpec{
+		Description: "foo",
+	})
+
+	require.NoError(t, st.Create(ctx, ns))
+
+	patched, err := st.Patch(ctx, ns.Metadata(), patch.Patch{
+		Type: patch.StrategicYAML,
+		Data: []byte("description: bar\n"),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, meta.NamespaceSpec{Description: "bar"}, patched.Spec())
+	assert.Equal(t, "2", patched.Metadata().Version().String())
+
+	r, err := st.Get(ctx, ns.Metadata())
+	require.NoError(t, err)
+
+	assert.True(t, resource.Equal(patched, r))
+
+	_, err = st.Patch(ctx, conformance.NewPathResource("default", "tmp").Metadata(), patch.Patch{})
+	assert.True(t, state.IsNotFoundError(err))
+}